@@ -23,26 +23,61 @@ import (
 	"go.uber.org/zap"
 
 	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/plog"
 )
 
 type batchLogs struct {
 	log          *zap.Logger
 	cfg          *Config
+	matcher      *profileMatcher
 	nextConsumer consumer.Logs
 	logData      map[string]plog.ResourceLogs
 	logCount     int
 	sizer        plog.Sizer
+	telemetry    *slLogFormatProcessorTelemetry
+	// partials holds the pending container log fragments and multiline
+	// the pending multiline group of each batch key.
+	partials  map[string]*pendingRecord
+	multiline map[string]*pendingRecord
+	// duplicates holds the open dedup window of each batch key.
+	dedup      *compiledDedup
+	duplicates map[string]*dedupWindow
+	rateLimit  *rateLimiter
 }
 
-func newBatchLogs(log *zap.Logger, cfg *Config, nextConsumer consumer.Logs) *batchLogs {
+func newBatchLogs(log *zap.Logger, cfg *Config, nextConsumer consumer.Logs) (*batchLogs, error) {
+	matcher, err := newProfileMatcher(cfg)
+	if err != nil {
+		return nil, err
+	}
+	dedup, err := compileDedup(cfg.Dedup)
+	if err != nil {
+		return nil, err
+	}
+	rateLimit, err := newRateLimiter(cfg.RateLimit)
+	if err != nil {
+		return nil, err
+	}
 	return &batchLogs{
 		log:          log,
 		cfg:          cfg,
+		matcher:      matcher,
 		nextConsumer: nextConsumer,
 		logData:      make(map[string]plog.ResourceLogs),
 		sizer:        &plog.ProtoMarshaler{},
-	}
+		partials:     make(map[string]*pendingRecord),
+		multiline:    make(map[string]*pendingRecord),
+		dedup:        dedup,
+		duplicates:   make(map[string]*dedupWindow),
+		rateLimit:    rateLimit,
+	}, nil
+}
+
+// setTelemetry records the processor's metrics from the batch and its profiles.
+func (bl *batchLogs) setTelemetry(telemetry *slLogFormatProcessorTelemetry) {
+	bl.telemetry = telemetry
+	bl.matcher.telemetry = telemetry
 }
 
 func (bl *batchLogs) export(ctx context.Context, sendBatchMaxSize int, returnBytes bool) (int, int, error) {
@@ -84,6 +119,32 @@ func (bl *batchLogs) itemCount() int {
 	return bl.logCount
 }
 
+// flush adds the pending container log fragments and multiline groups
+// whose flush timeout has passed, or all of them, to the batch, and
+// closes the dedup windows that have ended. Records shed by the rate
+// limit are reported once per report interval.
+func (bl *batchLogs) flush(all bool) {
+	now := timeNow()
+	for key, group := range bl.partials {
+		if all || !now.Before(group.deadline) {
+			bl.flushPartial(key, group)
+		}
+	}
+	for key, group := range bl.multiline {
+		if all || !now.Before(group.deadline) {
+			bl.flushMultiline(key, group)
+		}
+	}
+	for key, window := range bl.duplicates {
+		if all || !now.Before(window.deadline) {
+			bl.closeDedupWindow(key, window)
+		}
+	}
+	if bl.rateLimit != nil {
+		bl.rateLimit.report(now, bl.log, all)
+	}
+}
+
 func (bl *batchLogs) add(item any) {
 	ld := item.(plog.Logs)
 
@@ -97,14 +158,27 @@ func (bl *batchLogs) add(item any) {
 func (bl *batchLogs) addToBatch(ld plog.Logs) {
 
 	ld.ResourceLogs().RemoveIf(func(rl plog.ResourceLogs) bool {
+		// The resource part of the batch key and all resource level
+		// expressions are evaluated once for all records of the resource.
+		rlAttr := rl.Resource().Attributes()
+		keyBytes, err := json.Marshal(rlAttr.AsRaw())
+		if err != nil {
+			bl.log.Error("Field to marshal resource attributes",
+				zap.String("err", err.Error()))
+			return true
+		}
+		resKey := sha1.Sum(keyBytes)
+		res := bl.matcher.newResourceCache(rl)
 		rl.ScopeLogs().RemoveIf(func(ils plog.ScopeLogs) bool {
 			ils.LogRecords().RemoveIf(func(lr plog.LogRecord) bool {
-				gen, req, err := bl.cfg.MatchProfile(bl.log, rl, ils, lr)
+				profile, gen, req, err := bl.matcher.match(bl.log, res, ils, lr)
 				if err != nil {
 					switch err {
 					case errEmptyLine:
 						bl.log.Warn("Skipping log record",
 							zap.String("err", err.Error()))
+					case errDropped:
+						// counted by the records_dropped metric
 					default:
 						bl.log.Error("Failed to match profile",
 							zap.String("err", err.Error()))
@@ -120,34 +194,13 @@ func (bl *batchLogs) addToBatch(ld plog.Logs) {
 				}
 				h := sha1.New()
 				h.Write(reqBytes)
-				rlAttr := rl.Resource().Attributes()
-				keyBytes, err := json.Marshal(rlAttr.AsRaw())
-				if err != nil {
-					bl.log.Error("Field to marshal resource attributes",
-						zap.String("err", err.Error()))
-					return true
-				}
-				h.Write(keyBytes)
+				h.Write(resKey[:])
 				key := fmt.Sprintf("%x", h.Sum(nil))
-				dest, ok := bl.logData[key]
-				if !ok {
-					dest = plog.NewResourceLogs()
-					rlAttr.CopyTo(dest.Resource().Attributes())
-					dest.Resource().Attributes().PutStr("sl_service_group", gen.ServiceGroup)
-					dest.Resource().Attributes().PutStr("sl_host", gen.Host)
-					dest.Resource().Attributes().PutStr("sl_logbasename", req.Logbasename)
-					dest.Resource().Attributes().PutStr("sl_format", gen.Format)
-					dest.Resource().Attributes().PutStr("sl_metadata", string(reqBytes))
-					bl.logData[key] = dest
-				}
-				lr.Attributes().PutStr("sl_msg", gen.Message)
-				if dest.ScopeLogs().Len() < 1 {
-					_ = dest.ScopeLogs().AppendEmpty()
-				}
-				destIls := dest.ScopeLogs().At(0)
-				lr.MoveTo(destIls.LogRecords().AppendEmpty())
-				if destIls.LogRecords().Len() > bl.logCount {
-					bl.logCount = destIls.LogRecords().Len()
+				pkey := partialKey(key, gen)
+				if _, ok := bl.partials[pkey]; ok || gen.partial {
+					bl.addPartial(pkey, key, rlAttr, profile, gen, reqBytes, lr)
+				} else {
+					bl.addComplete(key, rlAttr, profile, gen, reqBytes, lr)
 				}
 				return true
 			})
@@ -157,6 +210,60 @@ func (bl *batchLogs) addToBatch(ld plog.Logs) {
 	})
 }
 
+// addComplete adds a record holding a complete message to the batch, or
+// to the multiline group of its stream.
+func (bl *batchLogs) addComplete(key string, rlAttr pcommon.Map, profile *compiledProfile, gen *ConfigResult, reqBytes []byte, lr plog.LogRecord) {
+	profile.redactContainerMessage(gen, bl.telemetry)
+	if profile.multiline != nil {
+		bl.addMultiline(key, rlAttr, profile, gen, reqBytes, lr)
+		return
+	}
+	bl.addMessage(key, rlAttr, profile, gen, reqBytes, lr)
+}
+
+// addMessage formats a record with a complete message and adds it to
+// the batch, unless it is suppressed as a duplicate or shed by the rate
+// limit of its stream.
+func (bl *batchLogs) addMessage(key string, rlAttr pcommon.Map, profile *compiledProfile, gen *ConfigResult, reqBytes []byte, lr plog.LogRecord) {
+	now := timeNow()
+	if bl.dedup != nil && bl.deduplicate(now, key, rlAttr, profile, gen, reqBytes, lr) {
+		return
+	}
+	if bl.rateLimit != nil && !bl.rateLimit.allow(now, key, gen, lr.SeverityNumber()) {
+		bl.telemetry.recordRecordsShed(gen)
+		// duplicates of a shed record are not summarized
+		delete(bl.duplicates, key)
+		return
+	}
+	profile.formatMessage(gen, lr)
+	bl.appendRecord(key, rlAttr, gen, reqBytes, lr)
+}
+
+// appendRecord moves a log record with its formatted message to the
+// batch of its stream.
+func (bl *batchLogs) appendRecord(key string, rlAttr pcommon.Map, gen *ConfigResult, reqBytes []byte, lr plog.LogRecord) {
+	dest, ok := bl.logData[key]
+	if !ok {
+		dest = plog.NewResourceLogs()
+		rlAttr.CopyTo(dest.Resource().Attributes())
+		dest.Resource().Attributes().PutStr("sl_service_group", gen.ServiceGroup)
+		dest.Resource().Attributes().PutStr("sl_host", gen.Host)
+		dest.Resource().Attributes().PutStr("sl_logbasename", gen.Logbasename)
+		dest.Resource().Attributes().PutStr("sl_format", gen.Format)
+		dest.Resource().Attributes().PutStr("sl_metadata", string(reqBytes))
+		bl.logData[key] = dest
+	}
+	lr.Attributes().PutStr("sl_msg", gen.Message)
+	if dest.ScopeLogs().Len() < 1 {
+		_ = dest.ScopeLogs().AppendEmpty()
+	}
+	destIls := dest.ScopeLogs().At(0)
+	lr.MoveTo(destIls.LogRecords().AppendEmpty())
+	if destIls.LogRecords().Len() > bl.logCount {
+		bl.logCount = destIls.LogRecords().Len()
+	}
+}
+
 func (bl *batchLogs) dumpLogRecord(rl plog.ResourceLogs, ils plog.ScopeLogs, lr plog.LogRecord) {
 	buf := dataBuffer{}
 	buf.logEntry("Resource SchemaURL: %s", rl.SchemaUrl())
@@ -0,0 +1,115 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sllogformatprocessor
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+func resourceConfig() *Config {
+	return &Config{
+		Profiles: []ConfigProfile{
+			{
+				ServiceGroup: &ConfigAttribute{
+					Exp:    &ConfigExpression{Source: "rattr:k8s.namespace.name"},
+					Rename: "ze_deployment_name",
+				},
+				Host: &ConfigAttribute{
+					Exp:    &ConfigExpression{Source: "rattr:k8s.node.name"},
+					Rename: "host",
+				},
+				Logbasename: &ConfigAttribute{
+					Exp: &ConfigExpression{
+						Op: CfgOpLc,
+						Exps: []*ConfigExpression{
+							{Source: "rattr:k8s.container.name"},
+						},
+					},
+					Rename: "logbasename",
+				},
+				Labels: []*ConfigAttribute{
+					{
+						Exp:    &ConfigExpression{Source: "rattr:k8s.pod.name"},
+						Rename: "zid_pod",
+					},
+				},
+				Message: &ConfigAttribute{
+					Exp: &ConfigExpression{Source: "body"},
+				},
+				Format: CfgFormatMessage,
+			},
+		},
+	}
+}
+
+func resourceLogs(numRecords int) plog.Logs {
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr("k8s.namespace.name", "prod")
+	rl.Resource().Attributes().PutStr("k8s.node.name", "node1")
+	rl.Resource().Attributes().PutStr("k8s.container.name", "Nginx")
+	rl.Resource().Attributes().PutStr("k8s.pod.name", "nginx-5d8f7c6b4-x2x9z")
+	ils := rl.ScopeLogs().AppendEmpty()
+	for i := 0; i < numRecords; i++ {
+		ils.LogRecords().AppendEmpty().Body().SetStr(fmt.Sprintf("GET /index.html %d", i))
+	}
+	return ld
+}
+
+func TestBatchLogsResource(t *testing.T) {
+	sink := new(consumertest.LogsSink)
+	bl, err := newBatchLogs(zap.NewNop(), resourceConfig(), sink)
+	require.NoError(t, err)
+	bl.add(resourceLogs(10))
+	bl.add(resourceLogs(5))
+	require.Len(t, bl.logData, 1)
+	assert.Equal(t, 15, bl.itemCount())
+
+	sent, _, err := bl.export(context.Background(), 0, false)
+	require.NoError(t, err)
+	assert.Equal(t, 15, sent)
+	rl := sink.AllLogs()[0].ResourceLogs().At(0)
+	host, _ := rl.Resource().Attributes().Get("sl_host")
+	assert.Equal(t, "node1", host.Str())
+	lbn, _ := rl.Resource().Attributes().Get("sl_logbasename")
+	assert.Equal(t, "nginx", lbn.Str())
+	msg, _ := rl.ScopeLogs().At(0).LogRecords().At(14).Attributes().Get("sl_msg")
+	assert.Equal(t, "GET /index.html 4", msg.Str())
+}
+
+func BenchmarkBatchLogsResource(b *testing.B) {
+	bl, err := newBatchLogs(zap.NewNop(), resourceConfig(), consumertest.NewNop())
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		ld := resourceLogs(1000)
+		b.StartTimer()
+		bl.add(ld)
+		if _, _, err := bl.export(context.Background(), 0, false); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,1178 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sllogformatprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/sllogformatprocessor"
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+// evalLevel describes how often the value of an expression can change.
+type evalLevel int
+
+const (
+	// levelConst expressions only read literals.
+	levelConst evalLevel = iota
+	// levelResource expressions read resource attributes and literals,
+	// their value is the same for all records of a ResourceLogs.
+	levelResource
+	// levelRecord expressions read the log record itself.
+	levelRecord
+)
+
+// evalFunc evaluates a compiled expression against the record held by the parser.
+type evalFunc func(p *Parser) string
+
+// compiledExp is the executable form of a ConfigExpression. Sources are
+// split and key paths parsed once, literal regular expressions are
+// compiled once and operators are resolved to closures.
+type compiledExp struct {
+	// id is the key path of the leftmost source, used as the default
+	// attribute name.
+	id    string
+	eval  evalFunc
+	level evalLevel
+	exps  []*compiledExp
+	// lit is set for literal sources, value then holds the literal.
+	lit   bool
+	value string
+}
+
+// profileCompiler holds the state shared by all profiles of a Config
+// while they are compiled.
+type profileCompiler struct {
+	grok *grokLibrary
+	// filter is applied to source values, it depends on the charset of
+	// the processor, profile or attribute being compiled.
+	filter func(string) string
+	// redact holds the processor's redaction rules.
+	redact redactRules
+	// pseudonymKey is the HMAC key of the pseudonymize op.
+	pseudonymKey []byte
+}
+
+func newProfileCompiler(cfg *Config) (*profileCompiler, error) {
+	grok, err := newGrokLibrary(cfg.GrokPatterns)
+	if err != nil {
+		return nil, err
+	}
+	key, err := loadPseudonymKey(cfg.Pseudonymize)
+	if err != nil {
+		return nil, err
+	}
+	redact, err := compileRedactRules("redact", cfg.Redact, key)
+	if err != nil {
+		return nil, err
+	}
+	c := &profileCompiler{grok: grok, filter: FilterASCII, redact: redact, pseudonymKey: key}
+	return c.withCharset(cfg.Charset), nil
+}
+
+// withCharset returns a compiler that filters source values for charset,
+// an empty charset keeps the filter.
+func (c *profileCompiler) withCharset(charset string) *profileCompiler {
+	filter, ok := charsetFilters[charset]
+	if !ok || charset == "" {
+		return c
+	}
+	scoped := *c
+	scoped.filter = filter
+	return &scoped
+}
+
+// opCompiler resolves an operator with its compiled argument expressions.
+type opCompiler func(c *profileCompiler, exps []*compiledExp) (evalFunc, error)
+
+var opCompilers map[string]opCompiler = map[string]opCompiler{
+	CfgOpRmprefix: func(c *profileCompiler, exps []*compiledExp) (evalFunc, error) {
+		a, b := exps[0], exps[1]
+		return func(p *Parser) string {
+			return strings.TrimPrefix(a.eval(p), b.eval(p))
+		}, nil
+	},
+	CfgOpRmsuffix: func(c *profileCompiler, exps []*compiledExp) (evalFunc, error) {
+		a, b := exps[0], exps[1]
+		return func(p *Parser) string {
+			return strings.TrimSuffix(a.eval(p), b.eval(p))
+		}, nil
+	},
+	CfgOpRmtail: func(c *profileCompiler, exps []*compiledExp) (evalFunc, error) {
+		a, b := exps[0], exps[1]
+		return func(p *Parser) string {
+			ret := a.eval(p)
+			idx := strings.LastIndex(ret, b.eval(p))
+			if idx > -1 {
+				ret = ret[:idx]
+			}
+			return ret
+		}, nil
+	},
+	CfgOpAlphaNum: func(c *profileCompiler, exps []*compiledExp) (evalFunc, error) {
+		a := exps[0]
+		return func(p *Parser) string {
+			return strings.Map(func(c rune) rune {
+				if unicode.IsUpper(c) || unicode.IsLower(c) || unicode.IsDigit(c) {
+					return c
+				}
+				return -1
+			}, a.eval(p))
+		}, nil
+	},
+	CfgOpLc: func(c *profileCompiler, exps []*compiledExp) (evalFunc, error) {
+		a := exps[0]
+		return func(p *Parser) string {
+			return strings.ToLower(a.eval(p))
+		}, nil
+	},
+	CfgOpUnescape: func(c *profileCompiler, exps []*compiledExp) (evalFunc, error) {
+		a := exps[0]
+		return func(p *Parser) string {
+			// Remove the ESC character. This is special-cased because
+			// embedding escapes into configurations/configs can be
+			// problematic.
+			return strings.ReplaceAll(a.eval(p), "\x1B", "")
+		}, nil
+	},
+	CfgOpReplace: func(_ *profileCompiler, exps []*compiledExp) (evalFunc, error) {
+		a, b, c := exps[0], exps[1], exps[2]
+		return func(p *Parser) string {
+			return strings.ReplaceAll(a.eval(p), b.eval(p), c.eval(p))
+		}, nil
+	},
+	CfgOpRegexp: func(c *profileCompiler, exps []*compiledExp) (evalFunc, error) {
+		a := exps[0]
+		re, err := regexpArg(exps[0].id, exps[1])
+		if err != nil {
+			return nil, err
+		}
+		return func(p *Parser) string {
+			ret := a.eval(p)
+			r := re(p)
+			if r == nil {
+				return ret
+			}
+			return regexpCaptures(r, ret)
+		}, nil
+	},
+	CfgOpAnd: func(c *profileCompiler, exps []*compiledExp) (evalFunc, error) {
+		return func(p *Parser) string {
+			ret := exps[0].eval(p)
+			for _, exp := range exps[1:] {
+				ret += ` ` + exp.eval(p)
+			}
+			return ret
+		}, nil
+	},
+	CfgOpOr: func(c *profileCompiler, exps []*compiledExp) (evalFunc, error) {
+		return func(p *Parser) string {
+			for _, exp := range exps {
+				if ret := exp.eval(p); ret != "" {
+					return ret
+				}
+			}
+			return ""
+		}, nil
+	},
+	CfgOpUc: func(c *profileCompiler, exps []*compiledExp) (evalFunc, error) {
+		a := exps[0]
+		return func(p *Parser) string {
+			return strings.ToUpper(a.eval(p))
+		}, nil
+	},
+	CfgOpTrim: func(c *profileCompiler, exps []*compiledExp) (evalFunc, error) {
+		a := exps[0]
+		return func(p *Parser) string {
+			return strings.TrimSpace(a.eval(p))
+		}, nil
+	},
+	CfgOpTrimset: func(c *profileCompiler, exps []*compiledExp) (evalFunc, error) {
+		a, b := exps[0], exps[1]
+		return func(p *Parser) string {
+			return strings.Trim(a.eval(p), b.eval(p))
+		}, nil
+	},
+	CfgOpSubstr: func(c *profileCompiler, exps []*compiledExp) (evalFunc, error) {
+		a := exps[0]
+		start, err := litInt(CfgOpSubstr, "start", exps[1])
+		if err != nil {
+			return nil, err
+		}
+		length, err := litInt(CfgOpSubstr, "length", exps[2])
+		if err != nil {
+			return nil, err
+		}
+		return func(p *Parser) string {
+			runes := []rune(a.eval(p))
+			from := start
+			if from < 0 {
+				from += len(runes)
+			}
+			if from < 0 {
+				from = 0
+			}
+			if from > len(runes) {
+				return ""
+			}
+			to := len(runes)
+			if length >= 0 && from+length < to {
+				to = from + length
+			}
+			return string(runes[from:to])
+		}, nil
+	},
+	CfgOpSplit: func(c *profileCompiler, exps []*compiledExp) (evalFunc, error) {
+		a, b := exps[0], exps[1]
+		index, err := litInt(CfgOpSplit, "index", exps[2])
+		if err != nil {
+			return nil, err
+		}
+		return func(p *Parser) string {
+			arr := strings.Split(a.eval(p), b.eval(p))
+			idx := index
+			if idx < 0 {
+				idx += len(arr)
+			}
+			if idx < 0 || idx >= len(arr) {
+				return ""
+			}
+			return arr[idx]
+		}, nil
+	},
+	CfgOpTruncate: func(c *profileCompiler, exps []*compiledExp) (evalFunc, error) {
+		a := exps[0]
+		length, err := litInt(CfgOpTruncate, "length", exps[1])
+		if err != nil {
+			return nil, err
+		}
+		if length < 0 {
+			return nil, fmt.Errorf("op %s length %d must not be negative", CfgOpTruncate, length)
+		}
+		return func(p *Parser) string {
+			ret := a.eval(p)
+			if len(ret) <= length {
+				return ret
+			}
+			runes := []rune(ret)
+			if len(runes) > length {
+				runes = runes[:length]
+			}
+			return string(runes)
+		}, nil
+	},
+	CfgOpFormat: func(c *profileCompiler, exps []*compiledExp) (evalFunc, error) {
+		template, args := exps[0], exps[1:]
+		// record data must not be used as the template
+		if !template.lit {
+			return nil, fmt.Errorf("op %s expects a literal template", CfgOpFormat)
+		}
+		verbs, err := countVerbs(template.value)
+		if err != nil {
+			return nil, fmt.Errorf("op %s template %s %s", CfgOpFormat, template.value, err.Error())
+		}
+		if verbs != len(args) {
+			return nil, fmt.Errorf("op %s template %s uses %d arguments for %d expressions", CfgOpFormat, template.value, verbs, len(args))
+		}
+		format := template.value
+		return func(p *Parser) string {
+			vals := make([]any, len(args))
+			for idx, exp := range args {
+				vals[idx] = exp.eval(p)
+			}
+			return fmt.Sprintf(format, vals...)
+		}, nil
+	},
+	CfgOpEq: func(c *profileCompiler, exps []*compiledExp) (evalFunc, error) {
+		a, b := exps[0], exps[1]
+		return func(p *Parser) string {
+			return condResult(a.eval(p) == b.eval(p))
+		}, nil
+	},
+	CfgOpNe: func(c *profileCompiler, exps []*compiledExp) (evalFunc, error) {
+		a, b := exps[0], exps[1]
+		return func(p *Parser) string {
+			return condResult(a.eval(p) != b.eval(p))
+		}, nil
+	},
+	CfgOpContains: func(c *profileCompiler, exps []*compiledExp) (evalFunc, error) {
+		a, b := exps[0], exps[1]
+		return func(p *Parser) string {
+			return condResult(strings.Contains(a.eval(p), b.eval(p)))
+		}, nil
+	},
+	CfgOpMatches: func(c *profileCompiler, exps []*compiledExp) (evalFunc, error) {
+		a := exps[0]
+		re, err := regexpArg(exps[0].id, exps[1])
+		if err != nil {
+			return nil, err
+		}
+		return func(p *Parser) string {
+			r := re(p)
+			return condResult(r != nil && r.MatchString(a.eval(p)))
+		}, nil
+	},
+	CfgOpIn: func(c *profileCompiler, exps []*compiledExp) (evalFunc, error) {
+		a, list := exps[0], exps[1:]
+		return func(p *Parser) string {
+			ret := a.eval(p)
+			for _, exp := range list {
+				if exp.eval(p) == ret {
+					return condTrue
+				}
+			}
+			return ""
+		}, nil
+	},
+	CfgOpIf: func(c *profileCompiler, exps []*compiledExp) (evalFunc, error) {
+		cond, a, b := exps[0], exps[1], exps[2]
+		return func(p *Parser) string {
+			if cond.eval(p) != "" {
+				return a.eval(p)
+			}
+			return b.eval(p)
+		}, nil
+	},
+	CfgOpGrok: func(c *profileCompiler, exps []*compiledExp) (evalFunc, error) {
+		a := exps[0]
+		if !exps[1].lit {
+			return nil, fmt.Errorf("op %s expects a literal pattern", CfgOpGrok)
+		}
+		re, err := c.grok.compile(exps[1].value)
+		if err != nil {
+			return nil, err
+		}
+		if len(exps) == 2 {
+			return func(p *Parser) string {
+				return grokFields(re, a.eval(p))
+			}, nil
+		}
+		if !exps[2].lit {
+			return nil, fmt.Errorf("op %s expects a literal field name", CfgOpGrok)
+		}
+		field := exps[2].value
+		if re.SubexpIndex(field) < 0 {
+			return nil, fmt.Errorf("op %s pattern %s has no field %s", CfgOpGrok, exps[1].value, field)
+		}
+		return func(p *Parser) string {
+			return grokField(re, a.eval(p), field)
+		}, nil
+	},
+	CfgOpKV: func(_ *profileCompiler, exps []*compiledExp) (evalFunc, error) {
+		a, key := exps[0], exps[1]
+		sep, delims := kvDefaultSep, ""
+		if len(exps) > 2 {
+			if !exps[2].lit || exps[2].value == "" {
+				return nil, fmt.Errorf("op %s expects a literal separator", CfgOpKV)
+			}
+			sep = exps[2].value
+		}
+		if len(exps) > 3 {
+			if !exps[3].lit {
+				return nil, fmt.Errorf("op %s expects literal delimiters", CfgOpKV)
+			}
+			delims = exps[3].value
+		}
+		return func(p *Parser) string {
+			return parseKV(a.eval(p), sep, delims)[key.eval(p)]
+		}, nil
+	},
+	CfgOpSyslog: func(_ *profileCompiler, exps []*compiledExp) (evalFunc, error) {
+		a := exps[0]
+		if !exps[1].lit {
+			return nil, fmt.Errorf("op %s expects a literal field", CfgOpSyslog)
+		}
+		field := exps[1].value
+		if err := validateSyslogField(field); err != nil {
+			return nil, err
+		}
+		return func(p *Parser) string {
+			return parseSyslog(a.eval(p)).field(field)
+		}, nil
+	},
+	CfgOpPseudonymize: compilePseudonymize,
+}
+
+// condTrue is the result of a condition that holds, any non-empty value
+// is considered true and the empty string false.
+const condTrue = "true"
+
+func condResult(ok bool) string {
+	if ok {
+		return condTrue
+	}
+	return ""
+}
+
+// regexpArg returns the regular expression held by an operator argument.
+// Literals are compiled once, other expressions for each record and nil is
+// returned if they don't compile.
+func regexpArg(id string, exp *compiledExp) (func(p *Parser) *regexp.Regexp, error) {
+	if exp.lit {
+		r, err := regexp.Compile(exp.value)
+		if err != nil {
+			return nil, fmt.Errorf("regular expression %s invalid - %s", exp.value, err.Error())
+		}
+		return func(*Parser) *regexp.Regexp { return r }, nil
+	}
+	return func(p *Parser) *regexp.Regexp {
+		pattern := exp.eval(p)
+		r, err := regexp.Compile(pattern)
+		if err != nil {
+			p.Log.Info("failed to compile regexp",
+				zap.String("id", id),
+				zap.String("value", pattern))
+			return nil
+		}
+		return r
+	}, nil
+}
+
+// litInt parses an integer argument of an operator, which must be a literal.
+func litInt(op, name string, exp *compiledExp) (int, error) {
+	if !exp.lit {
+		return 0, fmt.Errorf("op %s expects a literal integer for %s", op, name)
+	}
+	val, err := strconv.Atoi(exp.value)
+	if err != nil {
+		return 0, fmt.Errorf("op %s expects a literal integer for %s, got %s", op, name, exp.value)
+	}
+	return val, nil
+}
+
+// countVerbs returns the number of arguments used by a fmt template, the
+// highest argument of its verbs, widths and precisions taking explicit
+// argument indexes such as %[2]s into account.
+func countVerbs(template string) (int, error) {
+	count, argNum := 0, 0
+	// argIndex moves to an explicit argument index at idx, if any.
+	argIndex := func(idx int) (int, error) {
+		if idx >= len(template) || template[idx] != '[' {
+			return idx, nil
+		}
+		end := strings.IndexByte(template[idx:], ']')
+		if end < 0 {
+			return 0, errors.New("has an unterminated argument index")
+		}
+		n, err := strconv.Atoi(template[idx+1 : idx+end])
+		if err != nil || n < 1 {
+			return 0, fmt.Errorf("has invalid argument index %s", template[idx:idx+end+1])
+		}
+		argNum = n - 1
+		return idx + end + 1, nil
+	}
+	// useArg counts the argument at argNum and moves to the next one.
+	useArg := func() {
+		argNum++
+		if argNum > count {
+			count = argNum
+		}
+	}
+	var err error
+	for idx := 0; idx < len(template); idx++ {
+		if template[idx] != '%' {
+			continue
+		}
+		idx++
+		for idx < len(template) && strings.IndexByte("+-# 0", template[idx]) >= 0 {
+			idx++
+		}
+		if idx, err = argIndex(idx); err != nil {
+			return 0, err
+		}
+		if idx < len(template) && template[idx] == '*' {
+			useArg()
+			idx++
+		}
+		for idx < len(template) && template[idx] >= '0' && template[idx] <= '9' {
+			idx++
+		}
+		if idx < len(template) && template[idx] == '.' {
+			if idx, err = argIndex(idx + 1); err != nil {
+				return 0, err
+			}
+			if idx < len(template) && template[idx] == '*' {
+				useArg()
+				idx++
+			}
+			for idx < len(template) && template[idx] >= '0' && template[idx] <= '9' {
+				idx++
+			}
+		}
+		if idx, err = argIndex(idx); err != nil {
+			return 0, err
+		}
+		if idx >= len(template) {
+			return 0, errors.New("ends with an incomplete verb")
+		}
+		if template[idx] != '%' {
+			useArg()
+		}
+	}
+	return count, nil
+}
+
+// regexpCaptures concatenates all captures of the first match of r in the input.
+func regexpCaptures(r *regexp.Regexp, in string) string {
+	arr := r.FindStringSubmatch(in)
+	if len(arr) > 1 {
+		return strings.Join(arr[1:], "")
+	}
+	return ""
+}
+
+// compileSource compiles a source whose values are filtered to ASCII.
+func compileSource(source string) (*compiledExp, error) {
+	return compileFilteredSource(source, FilterASCII)
+}
+
+// compileFilteredSource compiles a source, filter is applied to the
+// values that are not generated by the processor.
+func compileFilteredSource(source string, filter func(string) string) (*compiledExp, error) {
+	arr := strings.SplitN(source, ":", 2)
+	exp := &compiledExp{level: levelRecord}
+	if len(arr) > 1 {
+		exp.id = arr[1]
+	}
+	key := exp.id
+	var path keyPath
+	switch arr[0] {
+	case CfgSourceRattr, CfgSourceAttr, CfgSourceBody:
+		var err error
+		if path, err = parseKeyPath(key); err != nil {
+			return nil, err
+		}
+	}
+	switch arr[0] {
+	case CfgSourceLit:
+		exp.level = levelConst
+		exp.lit = true
+		exp.value = filter(key)
+		value := exp.value
+		exp.eval = func(*Parser) string { return value }
+	case CfgSourceRattr:
+		exp.level = levelResource
+		exp.eval = func(p *Parser) string { return filter(evalMap(path, p.Rattr)) }
+	case CfgSourceAttr:
+		exp.eval = func(p *Parser) string { return filter(evalMap(path, p.Attr)) }
+	case CfgSourceBody:
+		exp.eval = func(p *Parser) string { return filter(p.evalBody(path)) }
+	case CfgSourceBodyJSON:
+		exp.eval = func(p *Parser) string { return p.evalBodyJSON() }
+	case CfgSourceScope:
+		// attr is followed by the key path of the attribute
+		field, attr, _ := strings.Cut(key, ".")
+		if _, ok := cfgScopeMap[field]; !ok || (field == CfgScopeAttr) != (attr != "") {
+			return nil, fmt.Errorf("invalid scope key %s, supported values %v, attr requires .<key path>", key, keysForMap(cfgScopeMap))
+		}
+		switch field {
+		case CfgScopeName:
+			exp.eval = func(p *Parser) string { return filter(p.Scope.Name()) }
+		case CfgScopeVersion:
+			exp.eval = func(p *Parser) string { return filter(p.Scope.Version()) }
+		case CfgScopeAttr:
+			attrPath, err := parseKeyPath(attr)
+			if err != nil {
+				return nil, err
+			}
+			exp.eval = func(p *Parser) string { return filter(evalMap(attrPath, p.Scope.Attributes())) }
+		}
+	case CfgSourceVar:
+		if key == "" {
+			return nil, fmt.Errorf("var requires the name of an extracted group")
+		}
+		exp.eval = func(p *Parser) string { return p.vars[key] }
+	case CfgSourceKV:
+		if key == "" {
+			return nil, fmt.Errorf("kv requires the name of a key")
+		}
+		exp.eval = func(p *Parser) string { return filter(p.evalKV(key)) }
+	case CfgSourceSyslog:
+		if err := validateSyslogField(key); err != nil {
+			return nil, err
+		}
+		exp.eval = func(p *Parser) string { return filter(p.evalSyslog(key)) }
+	case CfgSourceMeta:
+		if _, ok := cfgMetaMap[key]; !ok {
+			return nil, fmt.Errorf("invalid meta key %s, supported values %v", key, keysForMap(cfgMetaMap))
+		}
+		exp.eval = func(p *Parser) string { return filter(p.evalMeta(key)) }
+	default:
+		return nil, fmt.Errorf("invalid source %s, supported values %v", arr[0], keysForMap(cfgSourceMap))
+	}
+	return exp, nil
+}
+
+func (c *profileCompiler) compileExp(exp *ConfigExpression) (*compiledExp, error) {
+	if exp == nil {
+		return nil, nil
+	}
+	if exp.Source != "" {
+		return compileFilteredSource(exp.Source, c.filter)
+	}
+	compile, ok := opCompilers[exp.Op]
+	if !ok {
+		return nil, fmt.Errorf("invalid op %s, supported values %v", exp.Op, keysForMap(cfgOpMap))
+	}
+	numExps := cfgOpMap[exp.Op]
+	if (numExps == CMaxNumExps && len(exp.Exps) < 2) ||
+		(numExps != CMaxNumExps && (len(exp.Exps) < numExps || len(exp.Exps) > numExps+cfgOpOptionalExps[exp.Op])) {
+		return nil, fmt.Errorf("invalid number of expressions %d for op %s", len(exp.Exps), exp.Op)
+	}
+	exps := make([]*compiledExp, len(exp.Exps))
+	level := levelConst
+	for idx, exp2 := range exp.Exps {
+		var err error
+		if exps[idx], err = c.compileExp(exp2); err != nil {
+			return nil, err
+		}
+		if exps[idx] == nil {
+			return nil, fmt.Errorf("empty expression for op %s", exp.Op)
+		}
+		if exps[idx].level > level {
+			level = exps[idx].level
+		}
+	}
+	eval, err := compile(c, exps)
+	if err != nil {
+		return nil, err
+	}
+	return &compiledExp{id: exps[0].id, eval: eval, level: level, exps: exps}, nil
+}
+
+// compiledAttr is the executable form of a ConfigAttribute.
+type compiledAttr struct {
+	exp      *compiledExp
+	rename   string
+	validate *regexp.Regexp
+	// slot indexes the resource cache when the attribute only depends on
+	// the resource, -1 otherwise.
+	slot int
+}
+
+func (c *profileCompiler) compileAttr(idx int, name string, attribute *ConfigAttribute) (*compiledAttr, error) {
+	if attribute == nil {
+		return nil, nil
+	}
+	c = c.withCharset(attribute.Charset)
+	exp, err := c.compileExp(attribute.Exp)
+	if err != nil {
+		return nil, fmt.Errorf("profile %d %s has invalid expression - %s", idx, name, err.Error())
+	}
+	attr := &compiledAttr{
+		exp:    exp,
+		rename: attribute.Rename,
+		slot:   -1,
+	}
+	if attribute.Validate != "" {
+		attr.validate, err = regexp.Compile(attribute.Validate)
+		if err != nil {
+			return nil, fmt.Errorf("profile %d %s has invalid validate %s - %s", idx, name, attribute.Validate, err.Error())
+		}
+	}
+	return attr, nil
+}
+
+// eval returns the attribute name and value for the record held by the parser.
+func (a *compiledAttr) eval(p *Parser) (string, string) {
+	if a == nil {
+		return "", ""
+	}
+	if a.slot >= 0 && p.res != nil {
+		if !p.res.valid[a.slot] {
+			p.res.ids[a.slot], p.res.values[a.slot] = a.evalUncached(p)
+			p.res.valid[a.slot] = true
+		}
+		return p.res.ids[a.slot], p.res.values[a.slot]
+	}
+	return a.evalUncached(p)
+}
+
+func (a *compiledAttr) evalUncached(p *Parser) (string, string) {
+	var id, ret string
+	if a.exp != nil {
+		id, ret = a.exp.id, a.exp.eval(p)
+	}
+	if a.rename != "" {
+		id = a.rename
+	}
+	if a.validate != nil && !a.validate.MatchString(ret) {
+		p.Log.Info("failed to validate regexp",
+			zap.String("id", id),
+			zap.String("regexp", a.validate.String()),
+			zap.String("value", ret))
+		ret = ""
+	}
+	return id, ret
+}
+
+// compiledCondition is the executable form of a ConfigCondition.
+type compiledCondition struct {
+	desc   string
+	exp    *compiledExp
+	exists *bool
+	equals string
+	re     *regexp.Regexp
+}
+
+func (c *profileCompiler) compileCondition(idx int, name string, cond *ConfigCondition) (*compiledCondition, error) {
+	if cond == nil || cond.Exp == nil {
+		return nil, fmt.Errorf("profile %d %s requires exp", idx, name)
+	}
+	exp, err := c.compileExp(cond.Exp)
+	if err != nil {
+		return nil, fmt.Errorf("profile %d %s has invalid expression - %s", idx, name, err.Error())
+	}
+	cc := &compiledCondition{
+		desc:   cond.Exp.Source,
+		exp:    exp,
+		exists: cond.Exists,
+		equals: cond.Equals,
+	}
+	if cc.desc == "" {
+		cc.desc = "op " + cond.Exp.Op
+	}
+	if cond.Regexp != "" {
+		cc.re, err = regexp.Compile(cond.Regexp)
+		if err != nil {
+			return nil, fmt.Errorf("profile %d %s has invalid regexp %s - %s", idx, name, cond.Regexp, err.Error())
+		}
+	}
+	return cc, nil
+}
+
+// eval returns an empty string if the condition holds, otherwise the
+// predicate that failed.
+func (cc *compiledCondition) eval(p *Parser) string {
+	ret := cc.exp.eval(p)
+	switch {
+	case cc.exists != nil && *cc.exists != (ret != ""):
+		return fmt.Sprintf("%s exists %t", cc.desc, *cc.exists)
+	case cc.equals != "" && ret != cc.equals:
+		return fmt.Sprintf("%s equals %q, got %q", cc.desc, cc.equals, ret)
+	case cc.re != nil && !cc.re.MatchString(ret):
+		return fmt.Sprintf("%s regexp %q, got %q", cc.desc, cc.re.String(), ret)
+	case cc.exists == nil && cc.equals == "" && cc.re == nil && ret == "":
+		return fmt.Sprintf("%s is empty", cc.desc)
+	}
+	return ""
+}
+
+// compiledExtract is the executable form of a ConfigExtract.
+type compiledExtract struct {
+	exp   *compiledExp
+	re    *regexp.Regexp
+	names []string
+	// labels is set if the named groups are added as labels.
+	labels bool
+}
+
+func (c *profileCompiler) compileExtract(idx int, extract *ConfigExtract) (*compiledExtract, error) {
+	if extract == nil || extract.Exp == nil {
+		return nil, fmt.Errorf("profile %d extract requires exp", idx)
+	}
+	exp, err := c.compileExp(extract.Exp)
+	if err != nil {
+		return nil, fmt.Errorf("profile %d extract has invalid expression - %s", idx, err.Error())
+	}
+	var re *regexp.Regexp
+	switch {
+	case (extract.Regexp == "") == (extract.Grok == ""):
+		return nil, fmt.Errorf("profile %d extract must specify exactly one of regexp or grok", idx)
+	case extract.Grok != "":
+		if re, err = c.grok.compile(extract.Grok); err != nil {
+			return nil, fmt.Errorf("profile %d extract has invalid grok %s - %s", idx, extract.Grok, err.Error())
+		}
+	default:
+		if re, err = regexp.Compile(extract.Regexp); err != nil {
+			return nil, fmt.Errorf("profile %d extract has invalid regexp %s - %s", idx, extract.Regexp, err.Error())
+		}
+	}
+	ce := &compiledExtract{exp: exp, re: re, labels: extract.Labels == nil || *extract.Labels}
+	seen := map[string]bool{}
+	for _, name := range re.SubexpNames() {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			ce.names = append(ce.names, name)
+		}
+	}
+	if len(ce.names) == 0 {
+		return nil, fmt.Errorf("profile %d extract %s has no named groups", idx, extract.Regexp+extract.Grok)
+	}
+	return ce, nil
+}
+
+// eval stores the named groups in the parser's variables, nothing is
+// stored if the regular expression does not match.
+func (ce *compiledExtract) eval(p *Parser) {
+	match := ce.re.FindStringSubmatch(ce.exp.eval(p))
+	if match == nil {
+		return
+	}
+	for idx, name := range ce.re.SubexpNames() {
+		// Grok patterns may repeat a name in alternatives, keep the
+		// group that matched.
+		if _, ok := p.vars[name]; name != "" && (!ok || match[idx] != "") {
+			p.vars[name] = match[idx]
+		}
+	}
+}
+
+// checkVars verifies that all var sources refer to extracted groups.
+func checkVars(idx int, exp *ConfigExpression, names map[string]bool) error {
+	if exp == nil {
+		return nil
+	}
+	if name, ok := strings.CutPrefix(exp.Source, CfgSourceVar+":"); ok && !names[name] {
+		return fmt.Errorf("profile %d source %s does not refer to a named group of extract", idx, exp.Source)
+	}
+	for _, exp2 := range exp.Exps {
+		if err := checkVars(idx, exp2, names); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// profileExps returns the root expressions of the profile.
+func profileExps(profile *ConfigProfile) []*ConfigExpression {
+	var exps []*ConfigExpression
+	for _, cond := range profile.Match {
+		if cond != nil {
+			exps = append(exps, cond.Exp)
+		}
+	}
+	for _, rule := range profile.Drop {
+		if rule == nil {
+			continue
+		}
+		for _, cond := range rule.Match {
+			if cond != nil {
+				exps = append(exps, cond.Exp)
+			}
+		}
+	}
+	for _, extract := range profile.Extract {
+		if extract != nil {
+			exps = append(exps, extract.Exp)
+		}
+	}
+	attrs := []*ConfigAttribute{profile.ServiceGroup, profile.Host, profile.Logbasename, profile.Severity, profile.Message, profile.Tz}
+	attrs = append(attrs, profile.Labels...)
+	if profile.JSON != nil {
+		attrs = append(attrs, profile.JSON.Attributes...)
+	}
+	for _, attr := range attrs {
+		if attr != nil {
+			exps = append(exps, attr.Exp)
+		}
+	}
+	if profile.Timestamp != nil {
+		exps = append(exps, profile.Timestamp.Exp)
+	}
+	return exps
+}
+
+// compiledTimestamp is the executable form of a ConfigTimestamp.
+type compiledTimestamp struct {
+	exp     *compiledExp
+	layouts []timestampLayout
+}
+
+func (c *profileCompiler) compileTimestamp(idx int, ts *ConfigTimestamp) (*compiledTimestamp, error) {
+	if ts.Exp == nil {
+		return nil, fmt.Errorf("profile %d timestamp requires exp", idx)
+	}
+	exp, err := c.compileExp(ts.Exp)
+	if err != nil {
+		return nil, fmt.Errorf("profile %d timestamp has invalid expression - %s", idx, err.Error())
+	}
+	ct := &compiledTimestamp{exp: exp}
+	layouts := ts.Layouts
+	if len(layouts) == 0 {
+		layouts = []string{"rfc3339"}
+	}
+	for _, layout := range layouts {
+		parse, err := compileTimestampLayout(layout)
+		if err != nil {
+			return nil, fmt.Errorf("profile %d timestamp has invalid layout - %s", idx, err.Error())
+		}
+		ct.layouts = append(ct.layouts, parse)
+	}
+	return ct, nil
+}
+
+// parse returns the timestamp of the record held by the parser. ok is
+// false if the expression is empty, err is set if no layout matches.
+func (ct *compiledTimestamp) parse(p *Parser, loc *time.Location) (ts time.Time, ok bool, err error) {
+	value := strings.TrimSpace(ct.exp.eval(p))
+	if value == "" {
+		return ts, false, nil
+	}
+	for _, layout := range ct.layouts {
+		if ts, err = layout(value, loc); err == nil {
+			return ts, true, nil
+		}
+	}
+	return ts, true, fmt.Errorf("timestamp %q does not match any layout", value)
+}
+
+// compiledProfile is the executable form of a ConfigProfile.
+type compiledProfile struct {
+	match        []*compiledCondition
+	extract      []*compiledExtract
+	serviceGroup *compiledAttr
+	host         *compiledAttr
+	logbasename  *compiledAttr
+	severity     *compiledAttr
+	labels       []*compiledAttr
+	message      *compiledAttr
+	format       string
+	timestamp    *compiledTimestamp
+	tz           *compiledAttr
+	// severityRules map severity text, severityDetect enables detection
+	// of level tokens in the message.
+	severityRules  severityRules
+	severityDetect bool
+	multiline      *compiledMultiline
+	// partialTimeout flushes fragments of container log lines.
+	partialTimeout time.Duration
+	json           *compiledJSON
+	// filter is applied to values decoded from the message.
+	filter func(string) string
+	redact redactRules
+	// drop rules and minSeverity discard records matched by the profile.
+	drop        []*compiledDropRule
+	minSeverity plog.SeverityNumber
+}
+
+func (c *profileCompiler) compileProfile(idx int, profile *ConfigProfile) (*compiledProfile, error) {
+	var err error
+	if err = validateCfgString(idx, "preset", profile.Preset, cfgPresetMap); err != nil {
+		return nil, err
+	}
+	if preset, ok := profilePresets[profile.Preset]; ok {
+		// The preset fills in a copy, the configuration is unchanged
+		expanded := preset(*profile)
+		profile = &expanded
+	}
+	if err = validateCfgString(idx, "charset", profile.Charset, cfgCharsetMap); err != nil {
+		return nil, err
+	}
+	c = c.withCharset(profile.Charset)
+	cp := &compiledProfile{format: profile.Format, severityDetect: profile.SeverityDetect, filter: c.filter}
+	for _, cond := range profile.Match {
+		cc, err := c.compileCondition(idx, "match", cond)
+		if err != nil {
+			return nil, err
+		}
+		cp.match = append(cp.match, cc)
+	}
+	names := map[string]bool{}
+	for _, extract := range profile.Extract {
+		ce, err := c.compileExtract(idx, extract)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range ce.names {
+			names[name] = true
+		}
+		cp.extract = append(cp.extract, ce)
+	}
+	for _, exp := range profileExps(profile) {
+		if err := checkVars(idx, exp, names); err != nil {
+			return nil, err
+		}
+	}
+	if cp.serviceGroup, err = c.compileAttr(idx, "service_group", profile.ServiceGroup); err != nil {
+		return nil, err
+	}
+	if cp.host, err = c.compileAttr(idx, "host", profile.Host); err != nil {
+		return nil, err
+	}
+	if cp.logbasename, err = c.compileAttr(idx, "logbasename", profile.Logbasename); err != nil {
+		return nil, err
+	}
+	if cp.severity, err = c.compileAttr(idx, "severity", profile.Severity); err != nil {
+		return nil, err
+	}
+	if cp.message, err = c.compileAttr(idx, "message", profile.Message); err != nil {
+		return nil, err
+	}
+	if cp.tz, err = c.compileAttr(idx, "tz", profile.Tz); err != nil {
+		return nil, err
+	}
+	if cp.tz != nil && cp.tz.exp != nil && cp.tz.exp.lit {
+		if _, err := time.LoadLocation(cp.tz.exp.value); err != nil {
+			return nil, fmt.Errorf("profile %d tz has invalid zone %s - %s", idx, cp.tz.exp.value, err.Error())
+		}
+	}
+	for _, label := range profile.Labels {
+		attr, err := c.compileAttr(idx, "labels", label)
+		if err != nil {
+			return nil, err
+		}
+		cp.labels = append(cp.labels, attr)
+	}
+	if cp.severityRules, err = compileSeverityRules(idx, profile.SeverityMap); err != nil {
+		return nil, err
+	}
+	if profile.Timestamp != nil {
+		if cp.timestamp, err = c.compileTimestamp(idx, profile.Timestamp); err != nil {
+			return nil, err
+		}
+	}
+	if profile.Multiline != nil {
+		if cp.multiline, err = compileMultiline(idx, profile.Multiline); err != nil {
+			return nil, err
+		}
+	}
+	if cp.partialTimeout, err = compilePartialTimeout(idx, profile.PartialTimeout); err != nil {
+		return nil, err
+	}
+	if profile.Format == CfgFormatJSON {
+		if cp.json, err = c.compileJSON(idx, profile.JSON); err != nil {
+			return nil, err
+		}
+	}
+	redact, err := compileRedactRules(fmt.Sprintf("profile %d redact", idx), profile.Redact, c.pseudonymKey)
+	if err != nil {
+		return nil, err
+	}
+	cp.redact = append(append(cp.redact, c.redact...), redact...)
+	if cp.drop, err = c.compileDropRules(idx, profile.Drop); err != nil {
+		return nil, err
+	}
+	if cp.minSeverity, err = compileMinSeverity(idx, profile.MinSeverity); err != nil {
+		return nil, err
+	}
+	return cp, nil
+}
+
+// attrs returns all compiled attributes of the profile.
+func (cp *compiledProfile) attrs() []*compiledAttr {
+	attrs := []*compiledAttr{cp.serviceGroup, cp.host, cp.logbasename, cp.severity, cp.message, cp.tz}
+	attrs = append(attrs, cp.labels...)
+	if cp.json != nil {
+		attrs = append(attrs, cp.json.attrs...)
+	}
+	return attrs
+}
+
+// profileMatcher matches log records against the compiled profiles of a Config.
+type profileMatcher struct {
+	profiles  []*compiledProfile
+	telemetry *slLogFormatProcessorTelemetry
+	zones     zoneCache
+	// numSlots is the number of values held by a resourceCache.
+	numSlots int
+}
+
+// newProfileMatcher compiles all configured profiles.
+func newProfileMatcher(cfg *Config) (*profileMatcher, error) {
+	c, err := newProfileCompiler(cfg)
+	if err != nil {
+		return nil, err
+	}
+	m := &profileMatcher{
+		profiles: make([]*compiledProfile, len(cfg.Profiles)),
+	}
+	for idx := range cfg.Profiles {
+		cp, err := c.compileProfile(idx, &cfg.Profiles[idx])
+		if err != nil {
+			return nil, err
+		}
+		m.profiles[idx] = cp
+		for _, attr := range cp.attrs() {
+			m.cacheResourceAttr(attr)
+		}
+		for _, cc := range cp.match {
+			m.cacheResourceExp(cc.exp)
+		}
+		for _, rule := range cp.drop {
+			for _, cc := range rule.match {
+				m.cacheResourceExp(cc.exp)
+			}
+		}
+		for _, ce := range cp.extract {
+			m.cacheResourceExp(ce.exp)
+		}
+		if cp.timestamp != nil {
+			m.cacheResourceExp(cp.timestamp.exp)
+		}
+	}
+	return m, nil
+}
+
+// cacheResourceAttr assigns resource cache slots to the attribute, or to
+// its largest sub-expressions, that only depend on the resource.
+func (m *profileMatcher) cacheResourceAttr(attr *compiledAttr) {
+	if attr == nil || attr.exp == nil {
+		return
+	}
+	if attr.exp.level == levelResource {
+		attr.slot = m.numSlots
+		m.numSlots++
+		return
+	}
+	m.cacheResourceExp(attr.exp)
+}
+
+func (m *profileMatcher) cacheResourceExp(exp *compiledExp) {
+	if exp.level != levelResource {
+		for _, exp2 := range exp.exps {
+			m.cacheResourceExp(exp2)
+		}
+		return
+	}
+	slot := m.numSlots
+	m.numSlots++
+	eval := exp.eval
+	exp.eval = func(p *Parser) string {
+		if p.res == nil {
+			return eval(p)
+		}
+		if !p.res.valid[slot] {
+			p.res.values[slot] = eval(p)
+			p.res.valid[slot] = true
+		}
+		return p.res.values[slot]
+	}
+}
+
+// resourceCache holds the values of expressions that only depend on the
+// resource, so they are evaluated once for all records of a ResourceLogs.
+type resourceCache struct {
+	rattr  pcommon.Map
+	ids    []string
+	values []string
+	valid  []bool
+}
+
+func (m *profileMatcher) newResourceCache(rl plog.ResourceLogs) *resourceCache {
+	return &resourceCache{
+		rattr:  rl.Resource().Attributes(),
+		ids:    make([]string, m.numSlots),
+		values: make([]string, m.numSlots),
+		valid:  make([]bool, m.numSlots),
+	}
+}
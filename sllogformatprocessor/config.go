@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configopaque"
 )
 
 // Config defines configuration for batch processor.
@@ -39,6 +40,66 @@ type Config struct {
 	// Larger batches are split into smaller units.
 	// Default value is 0, that means no maximum size.
 	SendBatchMaxSize uint32 `mapstructure:"send_batch_max_size"`
+
+	// GrokPatterns adds named patterns to the built-in grok library, or
+	// replaces built-in ones with the same name.
+	GrokPatterns map[string]string `mapstructure:"grok_patterns"`
+
+	// Charset selects how characters that are not ASCII are handled in
+	// values read from log records, profiles and attributes can override
+	// it. The default ascii drops them.
+	Charset string `mapstructure:"charset"`
+
+	// Redact masks sensitive values in the message, ids and labels of
+	// all profiles, before the rules of the profile.
+	Redact []*ConfigRedactRule `mapstructure:"redact"`
+
+	// Pseudonymize holds the key of the pseudonymize op.
+	Pseudonymize *ConfigPseudonymize `mapstructure:"pseudonymize"`
+
+	// Dedup collapses identical messages of a stream.
+	Dedup *ConfigDedup `mapstructure:"dedup"`
+
+	// RateLimit limits the records of each stream by severity.
+	RateLimit *ConfigRateLimit `mapstructure:"rate_limit"`
+}
+
+// ConfigPseudonymize sets the HMAC key of the pseudonymize op, either
+// directly or read from key_file. Collectors sharing the key map a value
+// to the same pseudonym.
+type ConfigPseudonymize struct {
+	Key     configopaque.String `mapstructure:"key"`
+	KeyFile string              `mapstructure:"key_file"`
+}
+
+// ConfigDedup suppresses records of a stream whose message repeats the
+// first message of the window, a summary record reports the number of
+// suppressed records when the window closes. Ignore lists the parts of
+// messages that are not compared: digits, timestamps or uuids.
+type ConfigDedup struct {
+	Window time.Duration `mapstructure:"window"`
+	Ignore []string      `mapstructure:"ignore"`
+}
+
+// ConfigRateLimit sets the token bucket budget of each stream for records
+// below INFO, debug, and for INFO records or records without severity,
+// info. Records of WARN and above and of classes without budget are not
+// limited. Records over budget are dropped, or kept with probability
+// sample_ratio in sample mode. The records shed by each stream are
+// logged every report_interval.
+type ConfigRateLimit struct {
+	Debug          *ConfigRateBudget `mapstructure:"debug"`
+	Info           *ConfigRateBudget `mapstructure:"info"`
+	Mode           string            `mapstructure:"mode"`
+	SampleRatio    float64           `mapstructure:"sample_ratio"`
+	ReportInterval time.Duration     `mapstructure:"report_interval"`
+}
+
+// ConfigRateBudget allows rate records per second with bursts of up to
+// burst records.
+type ConfigRateBudget struct {
+	Rate  float64 `mapstructure:"rate"`
+	Burst int     `mapstructure:"burst"`
 }
 
 var _ component.Config = (*Config)(nil)
@@ -48,9 +109,16 @@ const (
 	CfgSourceAttr      string = "attr"
 	CfgSourceBody      string = "body"
 	CfgSourceLit       string = "lit"
+	CfgSourceBodyJSON  string = "bodyjson"
+	CfgSourceScope     string = "scope"
+	CfgSourceMeta      string = "meta"
+	CfgSourceVar       string = "var"
+	CfgSourceKV        string = "kv"
+	CfgSourceSyslog    string = "syslog"
 	CfgFormatMessage   string = "message"
 	CfgFormatContainer string = "container"
 	CfgFormatEvent     string = "event"
+	CfgFormatJSON      string = "json"
 	CfgOpRmprefix      string = "rmprefix"
 	CfgOpRmsuffix      string = "rmsuffix"
 	CfgOpRmtail        string = "rmtail"
@@ -61,6 +129,24 @@ const (
 	CfgOpRegexp        string = "regexp"
 	CfgOpAnd           string = "and"
 	CfgOpOr            string = "or"
+	CfgOpUc            string = "uc"
+	CfgOpTrim          string = "trim"
+	CfgOpTrimset       string = "trimset"
+	CfgOpSubstr        string = "substr"
+	CfgOpSplit         string = "split"
+	CfgOpTruncate      string = "truncate"
+	CfgOpFormat        string = "format"
+	CfgOpEq            string = "eq"
+	CfgOpNe            string = "ne"
+	CfgOpContains      string = "contains"
+	CfgOpMatches       string = "matches"
+	CfgOpIn            string = "in"
+	CfgOpIf            string = "if"
+	CfgOpGrok          string = "grok"
+	CfgOpKV            string = "kv"
+	CfgOpSyslog        string = "syslog"
+	CfgOpPseudonymize  string = "pseudonymize"
+	CfgPresetSyslog    string = "syslog"
 )
 
 var cfgIdNames map[string]int = map[string]int{
@@ -70,31 +156,99 @@ var cfgIdNames map[string]int = map[string]int{
 }
 
 var cfgSourceMap map[string]int = map[string]int{
-	CfgSourceRattr: 0,
-	CfgSourceAttr:  0,
-	CfgSourceBody:  0,
-	CfgSourceLit:   0,
+	CfgSourceRattr:    0,
+	CfgSourceAttr:     0,
+	CfgSourceBody:     0,
+	CfgSourceLit:      0,
+	CfgSourceBodyJSON: 0,
+	CfgSourceScope:    0,
+	CfgSourceMeta:     0,
+	CfgSourceVar:      0,
+	CfgSourceKV:       0,
+	CfgSourceSyslog:   0,
+}
+
+const (
+	CfgScopeName    string = "name"
+	CfgScopeVersion string = "version"
+	CfgScopeAttr    string = "attr"
+)
+
+var cfgScopeMap map[string]int = map[string]int{
+	CfgScopeName:    0,
+	CfgScopeVersion: 0,
+	CfgScopeAttr:    0,
+}
+
+const (
+	CfgMetaSeverityText      string = "severity_text"
+	CfgMetaSeverityNumber    string = "severity_number"
+	CfgMetaTraceID           string = "trace_id"
+	CfgMetaSpanID            string = "span_id"
+	CfgMetaTimestamp         string = "timestamp"
+	CfgMetaObservedTimestamp string = "observed_timestamp"
+	CfgMetaFlags             string = "flags"
+)
+
+var cfgMetaMap map[string]int = map[string]int{
+	CfgMetaSeverityText:      0,
+	CfgMetaSeverityNumber:    0,
+	CfgMetaTraceID:           0,
+	CfgMetaSpanID:            0,
+	CfgMetaTimestamp:         0,
+	CfgMetaObservedTimestamp: 0,
+	CfgMetaFlags:             0,
+}
+
+var cfgPresetMap map[string]int = map[string]int{
+	CfgPresetSyslog: 0,
 }
 
 var cfgFormatMap map[string]int = map[string]int{
 	CfgFormatMessage:   0,
 	CfgFormatContainer: 0,
 	CfgFormatEvent:     0,
+	CfgFormatJSON:      0,
 }
 
 const CMaxNumExps = 10
 
 var cfgOpMap map[string]int = map[string]int{
-	CfgOpRmprefix: 2,
-	CfgOpRmsuffix: 2,
-	CfgOpRmtail:   2,
-	CfgOpAlphaNum: 1,
-	CfgOpLc:       1,
-	CfgOpReplace:  3,
-	CfgOpRegexp:   2,
-	CfgOpUnescape: 1,
-	CfgOpAnd:      CMaxNumExps,
-	CfgOpOr:       CMaxNumExps,
+	CfgOpRmprefix:     2,
+	CfgOpRmsuffix:     2,
+	CfgOpRmtail:       2,
+	CfgOpAlphaNum:     1,
+	CfgOpLc:           1,
+	CfgOpReplace:      3,
+	CfgOpRegexp:       2,
+	CfgOpUnescape:     1,
+	CfgOpAnd:          CMaxNumExps,
+	CfgOpOr:           CMaxNumExps,
+	CfgOpUc:           1,
+	CfgOpTrim:         1,
+	CfgOpTrimset:      2,
+	CfgOpSubstr:       3,
+	CfgOpSplit:        3,
+	CfgOpTruncate:     2,
+	CfgOpFormat:       CMaxNumExps,
+	CfgOpEq:           2,
+	CfgOpNe:           2,
+	CfgOpContains:     2,
+	CfgOpMatches:      2,
+	CfgOpIn:           CMaxNumExps,
+	CfgOpIf:           3,
+	CfgOpGrok:         2,
+	CfgOpKV:           2,
+	CfgOpSyslog:       2,
+	CfgOpPseudonymize: 1,
+}
+
+// cfgOpOptionalExps holds the number of optional trailing expressions of
+// an op on top of those in cfgOpMap.
+var cfgOpOptionalExps map[string]int = map[string]int{
+	CfgOpGrok:         1,
+	CfgOpKV:           2,
+	CfgOpPseudonymize: 2,
 }
 
 type ConfigExpression struct {
@@ -107,16 +261,113 @@ type ConfigAttribute struct {
 	Exp      *ConfigExpression `mapstructure:"exp"`
 	Rename   string            `mapstructure:"rename"`
 	Validate string            `mapstructure:"validate"`
+	Charset  string            `mapstructure:"charset"`
+}
+
+// ConfigCondition is a predicate on the value of an expression. All of
+// exists, equals and regexp that are set must hold, if none is set the
+// value must not be empty.
+type ConfigCondition struct {
+	Exp    *ConfigExpression `mapstructure:"exp"`
+	Exists *bool             `mapstructure:"exists"`
+	Equals string            `mapstructure:"equals"`
+	Regexp string            `mapstructure:"regexp"`
+}
+
+// ConfigExtract matches a regular expression with named groups, or a grok
+// pattern with named fields, against the value of an expression. Every
+// named group can be read with the var source in the profile's other
+// expressions and is added as a label unless Labels is false.
+type ConfigExtract struct {
+	Exp    *ConfigExpression `mapstructure:"exp"`
+	Regexp string            `mapstructure:"regexp"`
+	Grok   string            `mapstructure:"grok"`
+	Labels *bool             `mapstructure:"labels"`
+}
+
+// ConfigTimestamp parses the value of an expression as the timestamp of
+// the log record, the layouts are tried in order.
+type ConfigTimestamp struct {
+	Exp     *ConfigExpression `mapstructure:"exp"`
+	Layouts []string          `mapstructure:"layouts"`
+}
+
+// ConfigSeverityMapping selects a severity, an OpenTelemetry severity
+// name such as Warn2, a level name such as warning or a number from 1 to
+// 24, for values that equal one of text ignoring case, are numbers in one
+// of ranges such as 500-599 or match regexp.
+type ConfigSeverityMapping struct {
+	Severity string   `mapstructure:"severity"`
+	Text     []string `mapstructure:"text"`
+	Ranges   []string `mapstructure:"ranges"`
+	Regexp   string   `mapstructure:"regexp"`
+}
+
+// ConfigMultiline merges consecutive records of a stream into one
+// record. A record starts a new group if it matches start, or if it does
+// not match continuation. Groups are flushed when they reach max_lines or
+// max_bytes, or when no record is added for flush_timeout.
+type ConfigMultiline struct {
+	Start        string        `mapstructure:"start"`
+	Continuation string        `mapstructure:"continuation"`
+	MaxLines     int           `mapstructure:"max_lines"`
+	MaxBytes     int           `mapstructure:"max_bytes"`
+	FlushTimeout time.Duration `mapstructure:"flush_timeout"`
+}
+
+// ConfigJSON selects the fields of the json format. Attributes are
+// added under their rename, or the key path, as labels are. Keys of an
+// object message that conflict with these fields are prefixed with
+// conflict_prefix.
+type ConfigJSON struct {
+	Fields         []string           `mapstructure:"fields"`
+	Attributes     []*ConfigAttribute `mapstructure:"attributes"`
+	ConflictPrefix string             `mapstructure:"conflict_prefix"`
+}
+
+// ConfigRedactRule replaces the values found by a built-in detector, or
+// by a regexp, with mask, with all but the last keep characters masked in
+// partial mode, or with a hash of the value. A regexp with groups only
+// replaces the first group.
+type ConfigRedactRule struct {
+	Name     string `mapstructure:"name"`
+	Detector string `mapstructure:"detector"`
+	Regexp   string `mapstructure:"regexp"`
+	Mode     string `mapstructure:"mode"`
+	Mask     string `mapstructure:"mask"`
+	Keep     int    `mapstructure:"keep"`
+}
+
+// ConfigDropRule discards the records of a profile for which all match
+// conditions hold. The name identifies the rule in the records_dropped
+// metric, it defaults to the index of the rule.
+type ConfigDropRule struct {
+	Name  string             `mapstructure:"name"`
+	Match []*ConfigCondition `mapstructure:"match"`
 }
 
 type ConfigProfile struct {
-	ServiceGroup *ConfigAttribute   `mapstructure:"service_group"`
-	Host         *ConfigAttribute   `mapstructure:"host"`
-	Logbasename  *ConfigAttribute   `mapstructure:"logbasename"`
-	Severity     *ConfigAttribute   `mapstructure:"severity"`
-	Labels       []*ConfigAttribute `mapstructure:"labels"`
-	Message      *ConfigAttribute   `mapstructure:"message"`
-	Format       string             `mapstructure:"format"`
+	Match          []*ConfigCondition       `mapstructure:"match"`
+	Extract        []*ConfigExtract         `mapstructure:"extract"`
+	ServiceGroup   *ConfigAttribute         `mapstructure:"service_group"`
+	Host           *ConfigAttribute         `mapstructure:"host"`
+	Logbasename    *ConfigAttribute         `mapstructure:"logbasename"`
+	Severity       *ConfigAttribute         `mapstructure:"severity"`
+	Labels         []*ConfigAttribute       `mapstructure:"labels"`
+	Message        *ConfigAttribute         `mapstructure:"message"`
+	Format         string                   `mapstructure:"format"`
+	Timestamp      *ConfigTimestamp         `mapstructure:"timestamp"`
+	Tz             *ConfigAttribute         `mapstructure:"tz"`
+	SeverityMap    []*ConfigSeverityMapping `mapstructure:"severity_map"`
+	SeverityDetect bool                     `mapstructure:"severity_detect"`
+	Preset         string                   `mapstructure:"preset"`
+	Multiline      *ConfigMultiline         `mapstructure:"multiline"`
+	PartialTimeout time.Duration            `mapstructure:"partial_timeout"`
+	JSON           *ConfigJSON              `mapstructure:"json"`
+	Charset        string                   `mapstructure:"charset"`
+	Redact         []*ConfigRedactRule      `mapstructure:"redact"`
+	Drop           []*ConfigDropRule        `mapstructure:"drop"`
+	MinSeverity    string                   `mapstructure:"min_severity"`
 }
 
 func keysForMap(mymap map[string]int) []string {
@@ -153,23 +404,32 @@ func validateProfileExp(idx int, name string, exp *ConfigExpression) error {
 			return err
 		}
 	}
+	if exp.Source != "" {
+		if _, err := compileSource(exp.Source); err != nil {
+			return fmt.Errorf("profile %d invalid value %s for %s, %s", idx, exp.Source, name, err.Error())
+		}
+	}
 	err := validateCfgString(idx, "op", exp.Op, cfgOpMap)
 	if err != nil {
 		return err
 	}
 	if exp.Op != "" {
 		numExps, _ := cfgOpMap[exp.Op]
-		if numExps != CMaxNumExps && len(exp.Exps) != numExps {
+		optional := cfgOpOptionalExps[exp.Op]
+		if numExps != CMaxNumExps && optional == 0 && len(exp.Exps) != numExps {
 			return fmt.Errorf("profile %d invalid number of expressions %d for op %s expecting %d", idx, len(exp.Exps), exp.Op, numExps)
 		}
+		if optional > 0 && (len(exp.Exps) < numExps || len(exp.Exps) > numExps+optional) {
+			return fmt.Errorf("profile %d invalid number of expressions %d for op %s expecting %d to %d", idx, len(exp.Exps), exp.Op, numExps, numExps+optional)
+		}
 		if numExps == CMaxNumExps && len(exp.Exps) < 2 {
 			return fmt.Errorf("profile %d invalid number of expressions %d for op %s expecting 2 or more", idx, len(exp.Exps), exp.Op)
 		}
-		if exp.Op == CfgOpRegexp &&
-			strings.HasPrefix(exp.Exps[1].Source, CfgSourceLit) {
-			_, err = regexp.Compile(exp.Exps[1].Source[len(CfgSourceLit)+1:])
-			if err != nil {
-				return fmt.Errorf("profile %d invalid value %s for %s, regular expression invalid", idx, exp.Exps[1].Source, name)
+		if exp.Op == CfgOpRegexp || exp.Op == CfgOpMatches {
+			if pattern, ok := strings.CutPrefix(exp.Exps[1].Source, CfgSourceLit+":"); ok {
+				if _, err = regexp.Compile(pattern); err != nil {
+					return fmt.Errorf("profile %d invalid value %s for %s, regular expression invalid", idx, exp.Exps[1].Source, name)
+				}
 			}
 		}
 	}
@@ -190,6 +450,11 @@ func validateProfileElem(idx int, name string, attribute *ConfigAttribute) error
 	if err != nil {
 		return err
 	}
+	if attribute.Charset != "" {
+		if _, ok := cfgCharsetMap[attribute.Charset]; !ok {
+			return fmt.Errorf("profile %d %s has invalid charset %s, supported values %v", idx, name, attribute.Charset, keysForMap(cfgCharsetMap))
+		}
+	}
 	if attribute.Validate != "" {
 		_, err = regexp.Compile(attribute.Validate)
 		if err != nil {
@@ -199,9 +464,56 @@ func validateProfileElem(idx int, name string, attribute *ConfigAttribute) error
 	return nil
 }
 
+func validateProfileCondition(idx int, name string, cond *ConfigCondition) error {
+	if cond == nil || cond.Exp == nil {
+		return fmt.Errorf("profile %d %s requires exp", idx, name)
+	}
+	if err := validateProfileExp(idx, name, cond.Exp); err != nil {
+		return err
+	}
+	if cond.Regexp != "" {
+		if _, err := regexp.Compile(cond.Regexp); err != nil {
+			return fmt.Errorf("profile %d %s has invalid regexp %s - %s", idx, name, cond.Regexp, err.Error())
+		}
+	}
+	return nil
+}
+
 // Validate checks if the processor configuration is valid
 func (cfg *Config) Validate() error {
+	if _, ok := cfgCharsetMap[cfg.Charset]; cfg.Charset != "" && !ok {
+		return fmt.Errorf("invalid value %s for charset, supported values %v", cfg.Charset, keysForMap(cfgCharsetMap))
+	}
+	if _, err := compileDedup(cfg.Dedup); err != nil {
+		return err
+	}
+	if _, err := newRateLimiter(cfg.RateLimit); err != nil {
+		return err
+	}
 	for idx, profile := range cfg.Profiles {
+		for _, cond := range profile.Match {
+			if err := validateProfileCondition(idx, "match", cond); err != nil {
+				return err
+			}
+		}
+		for _, rule := range profile.Drop {
+			if rule == nil {
+				continue
+			}
+			for _, cond := range rule.Match {
+				if err := validateProfileCondition(idx, "drop", cond); err != nil {
+					return err
+				}
+			}
+		}
+		for _, extract := range profile.Extract {
+			if extract == nil || extract.Exp == nil {
+				return fmt.Errorf("profile %d extract requires exp", idx)
+			}
+			if err := validateProfileExp(idx, "extract", extract.Exp); err != nil {
+				return err
+			}
+		}
 		if err := validateProfileElem(idx, "service_group", profile.ServiceGroup); err != nil {
 			return err
 		}
@@ -217,15 +529,44 @@ func (cfg *Config) Validate() error {
 		if err := validateProfileElem(idx, "message", profile.Message); err != nil {
 			return err
 		}
+		if err := validateProfileElem(idx, "tz", profile.Tz); err != nil {
+			return err
+		}
+		if profile.Timestamp != nil {
+			if profile.Timestamp.Exp == nil {
+				return fmt.Errorf("profile %d timestamp requires exp", idx)
+			}
+			if err := validateProfileExp(idx, "timestamp", profile.Timestamp.Exp); err != nil {
+				return err
+			}
+		}
 		err := validateCfgString(idx, "format", profile.Format, cfgFormatMap)
 		if err != nil {
 			return err
 		}
+		if err := validateCfgString(idx, "preset", profile.Preset, cfgPresetMap); err != nil {
+			return err
+		}
+		if err := validateCfgString(idx, "charset", profile.Charset, cfgCharsetMap); err != nil {
+			return err
+		}
 		for _, label := range profile.Labels {
 			if err := validateProfileElem(idx, "labels", label); err != nil {
 				return err
 			}
 		}
+		if profile.JSON != nil {
+			for _, attr := range profile.JSON.Attributes {
+				if err := validateProfileElem(idx, "json attributes", attr); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	// Profiles are compiled when the processor starts, catch anything
+	// that fails to compile here as well.
+	if _, err := newProfileMatcher(cfg); err != nil {
+		return err
 	}
 	if cfg.SendBatchMaxSize > 0 && cfg.SendBatchMaxSize < cfg.SendBatchSize {
 		return errors.New("send_batch_max_size must be greater or equal to send_batch_size")
@@ -159,3 +159,236 @@ func TestValidateConfig_ServiceGroup(t *testing.T) {
 	}
 	assert.Error(t, cfg.Validate())
 }
+
+func TestValidateConfig_ScopeAndMetaSources(t *testing.T) {
+	testCases := []struct {
+		source string
+		valid  bool
+	}{
+		{"scope:name", true},
+		{"scope:version", true},
+		{"scope:attr.log.source", true},
+		{"scope", false},
+		{"scope:attr", false},
+		{"scope:name.x", false},
+		{"scope:schema", false},
+		{"meta:trace_id", true},
+		{"meta:observed_timestamp", true},
+		{"meta", false},
+		{"meta:body", false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.source, func(t *testing.T) {
+			cfg := &Config{
+				Profiles: []ConfigProfile{
+					{
+						Logbasename: &ConfigAttribute{
+							Exp: &ConfigExpression{Source: tc.source},
+						},
+					},
+				},
+			}
+			if tc.valid {
+				assert.NoError(t, cfg.Validate())
+			} else {
+				assert.Error(t, cfg.Validate())
+			}
+		})
+	}
+}
+
+func TestValidateConfig_KeyPath(t *testing.T) {
+	cfg := &Config{
+		Profiles: []ConfigProfile{
+			{
+				Host: &ConfigAttribute{
+					Exp: &ConfigExpression{Source: `body:event_data["Computer"]`},
+				},
+				Logbasename: &ConfigAttribute{
+					Exp: &ConfigExpression{Source: "body:items[0"},
+				},
+			},
+		},
+	}
+	assert.Error(t, cfg.Validate())
+	cfg.Profiles[0].Logbasename.Exp.Source = "body:items[-1].name"
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestValidateConfig_StringOperators(t *testing.T) {
+	testCases := []struct {
+		name  string
+		exp   *ConfigExpression
+		valid bool
+	}{
+		{"uc", op(CfgOpUc, lit("a")), true},
+		{"uc arity", op(CfgOpUc, lit("a"), lit("b")), false},
+		{"trimset arity", op(CfgOpTrimset, lit("a")), false},
+		{"substr", op(CfgOpSubstr, lit("abc"), lit("1"), lit("1")), true},
+		{"substr arity", op(CfgOpSubstr, lit("abc"), lit("1")), false},
+		{"substr start not a number", op(CfgOpSubstr, lit("abc"), lit("x"), lit("1")), false},
+		{"substr length not a literal", op(CfgOpSubstr, lit("abc"), lit("1"), &ConfigExpression{Source: "attr:len"}), false},
+		{"split index", op(CfgOpSplit, lit("a.b"), lit("."), lit("one")), false},
+		{"truncate negative", op(CfgOpTruncate, lit("abc"), lit("-1")), false},
+		{"format", op(CfgOpFormat, lit("%s/%s"), lit("a"), lit("b")), true},
+		{"format verbs", op(CfgOpFormat, lit("%s/%s"), lit("a")), false},
+		{"format arity", op(CfgOpFormat, lit("%s")), false},
+		{"format indexes", op(CfgOpFormat, lit("%[2]s/%[1]s/%[2]s"), lit("a"), lit("b")), true},
+		{"format index verbs", op(CfgOpFormat, lit("%[1]s"), lit("a"), lit("b")), false},
+		{"format flags", op(CfgOpFormat, lit("%-5s|%.2[1]s|%#q"), lit("a"), lit("b")), true},
+		{"format invalid index", op(CfgOpFormat, lit("%[0]s"), lit("a")), false},
+		{"format incomplete verb", op(CfgOpFormat, lit("%s %"), lit("a")), false},
+		{"regexp bare lit", op(CfgOpRegexp, lit("a"), &ConfigExpression{Source: CfgSourceLit}), true},
+		{"matches bare lit", op(CfgOpMatches, lit("a"), &ConfigExpression{Source: CfgSourceLit}), true},
+		{"regexp invalid", op(CfgOpRegexp, lit("a"), lit("(")), false},
+		{"format template not a literal", op(CfgOpFormat, &ConfigExpression{Source: "body"}, lit("a")), false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{
+				Profiles: []ConfigProfile{
+					{
+						Logbasename: &ConfigAttribute{Exp: tc.exp},
+					},
+				},
+			}
+			if tc.valid {
+				assert.NoError(t, cfg.Validate())
+			} else {
+				assert.Error(t, cfg.Validate())
+			}
+		})
+	}
+}
+
+func TestValidateConfig_ConditionalOperators(t *testing.T) {
+	testCases := []struct {
+		name  string
+		exp   *ConfigExpression
+		valid bool
+	}{
+		{"if", op(CfgOpIf, op(CfgOpEq, lit("a"), lit("b")), lit("c"), lit("d")), true},
+		{"if arity", op(CfgOpIf, lit("a"), lit("b")), false},
+		{"eq arity", op(CfgOpEq, lit("a")), false},
+		{"in arity", op(CfgOpIn, lit("a")), false},
+		{"matches", op(CfgOpMatches, lit("a"), lit("^a+$")), true},
+		{"matches regexp", op(CfgOpMatches, lit("a"), lit("^(a")), false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{
+				Profiles: []ConfigProfile{
+					{
+						Logbasename: &ConfigAttribute{Exp: tc.exp},
+					},
+				},
+			}
+			if tc.valid {
+				assert.NoError(t, cfg.Validate())
+			} else {
+				assert.Error(t, cfg.Validate())
+			}
+		})
+	}
+}
+
+func TestValidateConfig_Match(t *testing.T) {
+	cfg := &Config{
+		Profiles: []ConfigProfile{
+			{
+				Match: []*ConfigCondition{
+					{
+						Exp:    &ConfigExpression{Source: "scope:name"},
+						Regexp: "^otelcol/(",
+					},
+				},
+			},
+		},
+	}
+	assert.Error(t, cfg.Validate())
+	cfg.Profiles[0].Match[0].Regexp = "^otelcol/"
+	assert.NoError(t, cfg.Validate())
+	cfg.Profiles[0].Match[0].Exp = nil
+	assert.Error(t, cfg.Validate())
+}
+
+func TestValidateConfig_Extract(t *testing.T) {
+	cfg := &Config{
+		Profiles: []ConfigProfile{
+			{
+				Extract: []*ConfigExtract{
+					{
+						Exp:    &ConfigExpression{Source: "attr:path"},
+						Regexp: `^/var/log/(\w+)`,
+					},
+				},
+			},
+		},
+	}
+	// no named groups
+	assert.Error(t, cfg.Validate())
+	cfg.Profiles[0].Extract[0].Regexp = `^/var/log/(?P<app>\w+`
+	assert.Error(t, cfg.Validate())
+	cfg.Profiles[0].Extract[0].Regexp = `^/var/log/(?P<app>\w+)`
+	assert.NoError(t, cfg.Validate())
+	cfg.Profiles[0].Logbasename = &ConfigAttribute{Exp: &ConfigExpression{Source: "var:application"}}
+	assert.Error(t, cfg.Validate())
+	cfg.Profiles[0].Logbasename.Exp.Source = "var:app"
+	assert.NoError(t, cfg.Validate())
+	cfg.Profiles[0].Extract[0].Exp = nil
+	assert.Error(t, cfg.Validate())
+}
+
+func TestValidateConfig_Grok(t *testing.T) {
+	cfg := &Config{
+		GrokPatterns: map[string]string{"APPID": `[A-Z]{3}-\d+`},
+		Profiles: []ConfigProfile{
+			{
+				Severity: &ConfigAttribute{Exp: &ConfigExpression{
+					Op: CfgOpGrok,
+					Exps: []*ConfigExpression{
+						{Source: "body"},
+						{Source: "lit:%{APPID:app} %{LOGLEVEL:level}"},
+						{Source: "lit:level"},
+					},
+				}},
+			},
+		},
+	}
+	assert.NoError(t, cfg.Validate())
+	cfg.Profiles[0].Severity.Exp.Exps = cfg.Profiles[0].Severity.Exp.Exps[:2]
+	assert.NoError(t, cfg.Validate())
+	cfg.Profiles[0].Severity.Exp.Exps = cfg.Profiles[0].Severity.Exp.Exps[:1]
+	assert.Error(t, cfg.Validate())
+	cfg.GrokPatterns["APPID"] = `%{APPID}`
+	cfg.Profiles[0].Severity.Exp.Exps = append(cfg.Profiles[0].Severity.Exp.Exps, &ConfigExpression{Source: "lit:%{LOGLEVEL}"})
+	assert.Error(t, cfg.Validate())
+}
+
+func TestValidateConfig_KV(t *testing.T) {
+	cfg := &Config{
+		Profiles: []ConfigProfile{
+			{
+				Severity: &ConfigAttribute{Exp: &ConfigExpression{Source: "kv:level"}},
+				Labels: []*ConfigAttribute{
+					{Exp: &ConfigExpression{
+						Op: CfgOpKV,
+						Exps: []*ConfigExpression{
+							{Source: "attr:event_data"},
+							{Source: "lit:SubjectUserName"},
+							{Source: "lit::"},
+							{Source: "lit:,"},
+						},
+					}},
+				},
+			},
+		},
+	}
+	assert.NoError(t, cfg.Validate())
+	cfg.Profiles[0].Labels[0].Exp.Exps = append(cfg.Profiles[0].Labels[0].Exp.Exps, &ConfigExpression{Source: "lit:x"})
+	assert.Error(t, cfg.Validate())
+	cfg.Profiles[0].Labels[0].Exp.Exps = cfg.Profiles[0].Labels[0].Exp.Exps[:2]
+	assert.NoError(t, cfg.Validate())
+	cfg.Profiles[0].Severity.Exp.Source = "kv:"
+	assert.Error(t, cfg.Validate())
+}
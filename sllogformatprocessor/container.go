@@ -0,0 +1,159 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sllogformatprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/sllogformatprocessor"
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+const (
+	// defaultPartialTimeout is the time after the last fragment of a
+	// container log line when the fragments are flushed.
+	defaultPartialTimeout = 5 * time.Second
+	// maxPartialBytes limits the size of a reassembled container log
+	// line.
+	maxPartialBytes = 1024 * 1024
+)
+
+// containerEntry is a decoded container log line.
+type containerEntry struct {
+	timestamp string
+	stream    string
+	log       string
+	partial   bool
+	// fragmentStream is the stream of a Docker entry, which is not part
+	// of the batch key.
+	fragmentStream string
+}
+
+// parseContainerEntry decodes a Docker json-file entry or a CRI log line.
+// The log of a Docker entry is filtered with filter.
+func parseContainerEntry(in string, filter func(string) string) (containerEntry, bool) {
+	if len(in) > 2 && in[0] == '{' {
+		return parseDockerJSON(in, filter)
+	}
+	return parseCRI(in)
+}
+
+// parseDockerJSON decodes an entry of the Docker json-file driver. Lines
+// longer than 16KB are split into several entries and only the last one
+// ends with a newline. The stream only separates the fragments of stdout
+// and stderr, so that the stream of Docker logs remains native.
+func parseDockerJSON(in string, filter func(string) string) (containerEntry, bool) {
+	var entry containerEntry
+	// The json-file driver writes the timestamp as time, other sources
+	// as timestamp. Objects without log are not container entries.
+	var contLog struct {
+		Log       *string `json:"log"`
+		Stream    string  `json:"stream"`
+		Timestamp string  `json:"timestamp"`
+		Time      string  `json:"time"`
+	}
+	if err := json.Unmarshal([]byte(in), &contLog); err != nil || contLog.Log == nil {
+		return entry, false
+	}
+	entry.timestamp = contLog.Timestamp
+	if entry.timestamp == "" {
+		entry.timestamp = contLog.Time
+	}
+	entry.fragmentStream = contLog.Stream
+	log, full := strings.CutSuffix(*contLog.Log, "\n")
+	entry.partial = !full
+	entry.log = filter(strings.TrimSuffix(log, "\r"))
+	return entry, true
+}
+
+// parseCRI decodes a line of the CRI log format written by containerd
+// and CRI-O, such as 2023-10-06T00:17:09.669794202Z stdout F message.
+// The tag F marks a full line and P a fragment of a longer line.
+func parseCRI(in string) (containerEntry, bool) {
+	var entry containerEntry
+	fields := strings.SplitN(in, " ", 4)
+	if len(fields) < 3 {
+		return entry, false
+	}
+	if _, err := time.Parse(time.RFC3339Nano, fields[0]); err != nil {
+		return entry, false
+	}
+	if fields[1] != "stdout" && fields[1] != "stderr" {
+		return entry, false
+	}
+	// Further tags are separated by colons
+	tag, _, _ := strings.Cut(fields[2], ":")
+	switch tag {
+	case "F":
+	case "P":
+		entry.partial = true
+	default:
+		return entry, false
+	}
+	entry.timestamp, entry.stream = fields[0], fields[1]
+	if len(fields) == 4 {
+		entry.log = fields[3]
+	}
+	return entry, true
+}
+
+// partialKey returns the key of the fragments of a record with the batch
+// key, which separates the stdout and stderr of Docker logs.
+func partialKey(key string, gen *ConfigResult) string {
+	if gen.fragmentStream == "" {
+		return key
+	}
+	return key + "/" + gen.fragmentStream
+}
+
+// addPartial adds a fragment of a container log line to the fragments
+// of its stream. The fragments are merged into the first record, which
+// keeps its timestamp, once the last fragment arrives.
+func (bl *batchLogs) addPartial(pkey, key string, rlAttr pcommon.Map, profile *compiledProfile, gen *ConfigResult, reqBytes []byte, lr plog.LogRecord) {
+	group, ok := bl.partials[pkey]
+	if ok {
+		group.message.WriteString(gen.Message)
+		group.lines++
+	} else {
+		group = newPendingRecord(rlAttr, profile, gen, reqBytes, lr)
+		group.key = key
+		bl.partials[pkey] = group
+	}
+	group.deadline = timeNow().Add(profile.partialTimeout)
+	if !gen.partial || group.message.Len() >= maxPartialBytes {
+		bl.flushPartial(pkey, group)
+	}
+}
+
+// flushPartial passes the reassembled line on as a complete message.
+func (bl *batchLogs) flushPartial(pkey string, group *pendingRecord) {
+	delete(bl.partials, pkey)
+	group.gen.Message = group.message.String()
+	group.gen.partial = false
+	bl.addComplete(group.key, group.resource, group.profile, group.gen, group.reqBytes, group.records.At(0))
+}
+
+func compilePartialTimeout(idx int, timeout time.Duration) (time.Duration, error) {
+	switch {
+	case timeout < 0:
+		return 0, fmt.Errorf("profile %d partial_timeout must not be negative", idx)
+	case timeout == 0:
+		return defaultPartialTimeout, nil
+	}
+	return timeout, nil
+}
@@ -0,0 +1,194 @@
+package sllogformatprocessor
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.uber.org/zap"
+)
+
+func TestParseCRI(t *testing.T) {
+	testcases := []struct {
+		name string
+		in   string
+		want containerEntry
+		ok   bool
+	}{
+		{
+			name: "full",
+			in:   "2023-10-06T00:17:09.669794202Z stdout F GET /index.html 200",
+			want: containerEntry{timestamp: "2023-10-06T00:17:09.669794202Z", stream: "stdout", log: "GET /index.html 200"},
+			ok:   true,
+		},
+		{
+			name: "partial",
+			in:   "2023-10-06T00:17:09.669794202Z stderr P first half ",
+			want: containerEntry{timestamp: "2023-10-06T00:17:09.669794202Z", stream: "stderr", log: "first half ", partial: true},
+			ok:   true,
+		},
+		{
+			name: "empty line",
+			in:   "2023-10-06T00:17:09Z stdout F",
+			want: containerEntry{timestamp: "2023-10-06T00:17:09Z", stream: "stdout"},
+			ok:   true,
+		},
+		{
+			name: "more tags",
+			in:   "2023-10-06T00:17:09Z stdout F:x msg",
+			want: containerEntry{timestamp: "2023-10-06T00:17:09Z", stream: "stdout", log: "msg"},
+			ok:   true,
+		},
+		{name: "bad timestamp", in: "yesterday stdout F msg"},
+		{name: "bad stream", in: "2023-10-06T00:17:09Z stdin F msg"},
+		{name: "bad tag", in: "2023-10-06T00:17:09Z stdout X msg"},
+		{name: "docker json", in: `{"log":"msg\n","stream":"stdout","time":"2023-10-06T00:17:09Z"}`},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			entry, ok := parseCRI(tc.in)
+			assert.Equal(t, tc.ok, ok)
+			if ok {
+				assert.Equal(t, tc.want, entry)
+			}
+		})
+	}
+}
+
+func containerConfig() *Config {
+	cfg := resourceConfig()
+	cfg.Profiles[0].Format = CfgFormatContainer
+	return cfg
+}
+
+func sentStreams(t *testing.T, sink *consumertest.LogsSink) map[string][]string {
+	streams := map[string][]string{}
+	for _, ld := range sink.AllLogs() {
+		for i := 0; i < ld.ResourceLogs().Len(); i++ {
+			rl := ld.ResourceLogs().At(i)
+			metadata, _ := rl.Resource().Attributes().Get("sl_metadata")
+			var req StreamTokenReq
+			require.NoError(t, json.Unmarshal([]byte(metadata.Str()), &req))
+			assert.True(t, req.ContainerLog)
+			lrs := rl.ScopeLogs().At(0).LogRecords()
+			for j := 0; j < lrs.Len(); j++ {
+				msg, _ := lrs.At(j).Attributes().Get("sl_msg")
+				streams[req.Stream] = append(streams[req.Stream], msg.Str())
+			}
+		}
+	}
+	return streams
+}
+
+func TestCRIReassembly(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 10, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	sink := new(consumertest.LogsSink)
+	bl, err := newBatchLogs(zap.NewNop(), containerConfig(), sink)
+	require.NoError(t, err)
+
+	bl.add(multilineLogs(
+		"2023-10-06T00:17:09.000000001Z stdout P first ",
+		"2023-10-06T00:17:09.000000002Z stderr F error line",
+		"2023-10-06T00:17:09.000000003Z stdout P second ",
+	))
+	bl.add(multilineLogs(
+		"2023-10-06T00:17:09.000000004Z stdout F third",
+		"2023-10-06T00:17:10Z stdout P dangling",
+	))
+	sentMessages(t, bl, sink)
+	assert.Equal(t, map[string][]string{
+		"stdout": {"2023-10-06T00:17:09.000000001Z first second third"},
+		"stderr": {"2023-10-06T00:17:09.000000002Z error line"},
+	}, sentStreams(t, sink))
+
+	// fragments without end are flushed after the timeout
+	bl.flush(false)
+	assert.Empty(t, sentMessages(t, bl, sink))
+	now = now.Add(defaultPartialTimeout)
+	bl.flush(false)
+	assert.Equal(t, []string{"2023-10-06T00:17:10Z dangling"}, sentMessages(t, bl, sink))
+}
+
+func TestDockerReassembly(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 10, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	cfg := containerConfig()
+	cfg.Profiles[0].PartialTimeout = time.Second
+	sink := new(consumertest.LogsSink)
+	bl, err := newBatchLogs(zap.NewNop(), cfg, sink)
+	require.NoError(t, err)
+
+	bl.add(multilineLogs(
+		`{"log":"hello\n","stream":"stdout","time":"2023-10-06T00:17:11Z"}`,
+		`{"log":"first\tpart ","stream":"stdout","time":"2023-10-06T00:17:12Z"}`,
+	))
+	bl.add(multilineLogs(
+		`{"log":"second part ","stream":"stdout","time":"2023-10-06T00:17:13Z"}`,
+		`{"log":"last part\r\n","stream":"stdout","time":"2023-10-06T00:17:14Z"}`,
+		`{"log":"dangling","stream":"stdout","timestamp":"2023-10-06T00:17:15Z"}`,
+	))
+	sentMessages(t, bl, sink)
+	assert.Equal(t, map[string][]string{"native": {
+		"2023-10-06T00:17:11Z hello",
+		`2023-10-06T00:17:12Z first\tpart second part last part`,
+	}}, sentStreams(t, sink))
+
+	now = now.Add(time.Second)
+	bl.flush(false)
+	assert.Equal(t, []string{"2023-10-06T00:17:15Z dangling"}, sentMessages(t, bl, sink))
+
+	// not a docker entry
+	bl.add(multilineLogs(`{"msg":"hello"}`, `{"log":`))
+	assert.Equal(t, []string{`{"msg":"hello"}`, `{"log":`}, sentMessages(t, bl, sink))
+}
+
+func TestDockerReassemblyStreams(t *testing.T) {
+	sink := new(consumertest.LogsSink)
+	bl, err := newBatchLogs(zap.NewNop(), containerConfig(), sink)
+	require.NoError(t, err)
+
+	bl.add(multilineLogs(
+		`{"log":"out first ","stream":"stdout","time":"2023-10-06T00:17:11Z"}`,
+		`{"log":"err first ","stream":"stderr","time":"2023-10-06T00:17:12Z"}`,
+		`{"log":"err line\n","stream":"stderr","time":"2023-10-06T00:17:13Z"}`,
+		`{"log":"out line\n","stream":"stdout","time":"2023-10-06T00:17:14Z"}`,
+		`{"log":"complete\n","stream":"stderr","time":"2023-10-06T00:17:15Z"}`,
+	))
+	// the fragments of each stream are reassembled in the native stream
+	sentMessages(t, bl, sink)
+	assert.Equal(t, map[string][]string{"native": {
+		"2023-10-06T00:17:12Z err first err line",
+		"2023-10-06T00:17:11Z out first out line",
+		"2023-10-06T00:17:15Z complete",
+	}}, sentStreams(t, sink))
+}
+
+func TestPartialTimeoutInvalid(t *testing.T) {
+	cfg := containerConfig()
+	cfg.Profiles[0].PartialTimeout = -time.Second
+	assert.ErrorContains(t, cfg.Validate(), "profile 0 partial_timeout must not be negative")
+}
+
+func TestCRIMultiline(t *testing.T) {
+	cfg := containerConfig()
+	cfg.Profiles[0].Multiline = &ConfigMultiline{Start: `^\S`}
+	sink := new(consumertest.LogsSink)
+	bl, err := newBatchLogs(zap.NewNop(), cfg, sink)
+	require.NoError(t, err)
+
+	bl.add(multilineLogs(
+		"2023-10-06T00:17:09Z stderr F Exception in thread main",
+		"2023-10-06T00:17:09Z stderr P     at com.",
+		"2023-10-06T00:17:09Z stderr F example.Main",
+	))
+	bl.flush(true)
+	assert.Equal(t, []string{`2023-10-06T00:17:09Z Exception in thread main\n    at com.example.Main`}, sentMessages(t, bl, sink))
+}
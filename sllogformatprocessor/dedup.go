@@ -0,0 +1,160 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sllogformatprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/sllogformatprocessor"
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+const (
+	CfgDedupIgnoreDigits     string = "digits"
+	CfgDedupIgnoreTimestamps string = "timestamps"
+	CfgDedupIgnoreUUIDs      string = "uuids"
+
+	defaultDedupWindow = 10 * time.Second
+	// dedupSummary is the message of the record that closes a window
+	// with suppressed duplicates.
+	dedupSummary = "last message repeated %d times"
+)
+
+var cfgDedupIgnoreMap map[string]int = map[string]int{
+	CfgDedupIgnoreDigits:     0,
+	CfgDedupIgnoreTimestamps: 0,
+	CfgDedupIgnoreUUIDs:      0,
+}
+
+// dedupIgnoreOrder applies the replacements of the ignore options from
+// the most to the least specific, so that timestamps and UUIDs are
+// replaced before their digits.
+var dedupIgnoreOrder = []struct {
+	name        string
+	re          *regexp.Regexp
+	replacement string
+}{
+	{
+		name:        CfgDedupIgnoreTimestamps,
+		re:          regexp.MustCompile(`\d{4}-\d{2}-\d{2}(?:[T ]\d{2}:\d{2}:\d{2}(?:[.,]\d+)?(?:Z|[+-]\d{2}:?\d{2})?)?|(?:Jan|Feb|Mar|Apr|May|Jun|Jul|Aug|Sep|Oct|Nov|Dec)\s+\d{1,2}\s+\d{2}:\d{2}:\d{2}|\b\d{2}:\d{2}:\d{2}(?:[.,]\d+)?\b`),
+		replacement: "<ts>",
+	},
+	{
+		name:        CfgDedupIgnoreUUIDs,
+		re:          regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`),
+		replacement: "<uuid>",
+	},
+	{
+		name:        CfgDedupIgnoreDigits,
+		re:          regexp.MustCompile(`\d+`),
+		replacement: "0",
+	},
+}
+
+// compiledDedup is the compiled form of a ConfigDedup.
+type compiledDedup struct {
+	window time.Duration
+	ignore []*regexp.Regexp
+	// replacements holds the replacement of each ignore regexp.
+	replacements []string
+}
+
+func compileDedup(cfg *ConfigDedup) (*compiledDedup, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	if cfg.Window < 0 {
+		return nil, errors.New("dedup window must not be negative")
+	}
+	d := &compiledDedup{window: cfg.Window}
+	if d.window == 0 {
+		d.window = defaultDedupWindow
+	}
+	ignore := make(map[string]bool)
+	for _, name := range cfg.Ignore {
+		if _, ok := cfgDedupIgnoreMap[name]; !ok {
+			return nil, fmt.Errorf("invalid value %s for dedup ignore, supported values %v", name, keysForMap(cfgDedupIgnoreMap))
+		}
+		ignore[name] = true
+	}
+	for _, opt := range dedupIgnoreOrder {
+		if ignore[opt.name] {
+			d.ignore = append(d.ignore, opt.re)
+			d.replacements = append(d.replacements, opt.replacement)
+		}
+	}
+	return d, nil
+}
+
+// normalize returns the message compared against the other messages of
+// its stream.
+func (d *compiledDedup) normalize(msg string) string {
+	for idx, re := range d.ignore {
+		msg = re.ReplaceAllLiteralString(msg, d.replacements[idx])
+	}
+	return msg
+}
+
+// dedupWindow holds the message of the first record of a stream in the
+// window and the last duplicate suppressed since.
+type dedupWindow struct {
+	message  string
+	deadline time.Time
+	repeated int
+	last     *pendingRecord
+}
+
+// deduplicate reports if a record with a complete message duplicates the
+// first message of the open window of its stream and holds it back,
+// otherwise the record opens a new window.
+func (bl *batchLogs) deduplicate(now time.Time, key string, rlAttr pcommon.Map, profile *compiledProfile, gen *ConfigResult, reqBytes []byte, lr plog.LogRecord) bool {
+	msg := bl.dedup.normalize(gen.Message)
+	window, ok := bl.duplicates[key]
+	if ok && now.Before(window.deadline) && window.message == msg {
+		window.repeated++
+		bl.telemetry.recordDuplicatesSuppressed(1)
+		if window.last == nil {
+			window.last = newPendingRecord(rlAttr, profile, gen, reqBytes, lr)
+		} else {
+			window.last.gen = gen
+			lr.MoveTo(window.last.records.At(0))
+		}
+		return true
+	}
+	if ok {
+		bl.closeDedupWindow(key, window)
+	}
+	bl.duplicates[key] = &dedupWindow{message: msg, deadline: now.Add(bl.dedup.window)}
+	return false
+}
+
+// closeDedupWindow adds a summary of the duplicates suppressed in a
+// window to the batch. The summary is the last duplicate with its
+// message replaced.
+func (bl *batchLogs) closeDedupWindow(key string, window *dedupWindow) {
+	delete(bl.duplicates, key)
+	if window.last == nil {
+		return
+	}
+	last := window.last
+	lr := last.records.At(0)
+	last.gen.Message = fmt.Sprintf(dedupSummary, window.repeated)
+	lr.Body().SetStr(last.gen.Message)
+	last.profile.formatMessage(last.gen, lr)
+	bl.appendRecord(key, last.resource, last.gen, last.reqBytes, lr)
+}
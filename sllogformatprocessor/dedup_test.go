@@ -0,0 +1,98 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sllogformatprocessor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.uber.org/zap"
+)
+
+func TestDedupNormalize(t *testing.T) {
+	in := "2024-03-01T10:00:00.123Z req 9f1c2d3e-4b5a-6789-abcd-ef0123456789 took 42ms at 10:00:01"
+	testcases := []struct {
+		ignore []string
+		want   string
+	}{
+		{ignore: nil, want: in},
+		{ignore: []string{CfgDedupIgnoreDigits}, want: "0-0-0T0:0:0.0Z req 0f0c0d0e-0b0a-0-abcd-ef0 took 0ms at 0:0:0"},
+		{ignore: []string{CfgDedupIgnoreTimestamps}, want: "<ts> req 9f1c2d3e-4b5a-6789-abcd-ef0123456789 took 42ms at <ts>"},
+		{ignore: []string{CfgDedupIgnoreDigits, CfgDedupIgnoreUUIDs, CfgDedupIgnoreTimestamps}, want: "<ts> req <uuid> took 0ms at <ts>"},
+	}
+	for _, tc := range testcases {
+		d, err := compileDedup(&ConfigDedup{Ignore: tc.ignore})
+		require.NoError(t, err)
+		assert.Equal(t, tc.want, d.normalize(in), "ignore %v", tc.ignore)
+	}
+
+	_, err := compileDedup(&ConfigDedup{Ignore: []string{"words"}})
+	assert.ErrorContains(t, err, "invalid value words for dedup ignore")
+	cfg := resourceConfig()
+	cfg.Dedup = &ConfigDedup{Window: -time.Second}
+	assert.ErrorContains(t, cfg.Validate(), "dedup window must not be negative")
+}
+
+func TestDedup(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 10, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	cfg := resourceConfig()
+	cfg.Dedup = &ConfigDedup{Window: time.Minute, Ignore: []string{CfgDedupIgnoreDigits}}
+	sink := new(consumertest.LogsSink)
+	bl, err := newBatchLogs(zap.NewNop(), cfg, sink)
+	require.NoError(t, err)
+	bpt, reader := newTestTelemetry(t)
+	bl.setTelemetry(bpt)
+
+	// a different message closes the window
+	bl.add(multilineLogs("connection refused pid=1", "connection refused pid=2", "connection refused pid=3", "restarting"))
+	assert.Equal(t, []string{"connection refused pid=1", "last message repeated 2 times", "restarting"}, sentMessages(t, bl, sink))
+
+	// the summary is sent when the window ends
+	bl.add(multilineLogs("restarting", "restarting"))
+	bl.flush(false)
+	assert.Empty(t, sentMessages(t, bl, sink))
+	now = now.Add(time.Minute)
+	bl.flush(false)
+	assert.Equal(t, []string{"last message repeated 2 times"}, sentMessages(t, bl, sink))
+
+	// a window without duplicates ends quietly, the next message opens a new window
+	bl.add(multilineLogs("restarting"))
+	now = now.Add(time.Minute)
+	bl.add(multilineLogs("restarting"))
+	bl.flush(true)
+	assert.Equal(t, []string{"restarting", "restarting"}, sentMessages(t, bl, sink))
+	assert.Empty(t, bl.duplicates)
+
+	assert.Equal(t, map[string]int64{bpt.processorAttr[0].Value.Emit(): 4}, counterValues(t, reader, "duplicates_suppressed", processorKey))
+}
+
+func TestDedupMultiline(t *testing.T) {
+	cfg := resourceConfig()
+	cfg.Dedup = &ConfigDedup{}
+	cfg.Profiles[0].Multiline = &ConfigMultiline{Start: `^\S`}
+	sink := new(consumertest.LogsSink)
+	bl, err := newBatchLogs(zap.NewNop(), cfg, sink)
+	require.NoError(t, err)
+
+	bl.add(multilineLogs("panic: boom", "  goroutine 1", "panic: boom", "  goroutine 1", "panic: boom", "  goroutine 2"))
+	bl.flush(true)
+	assert.Equal(t, []string{`panic: boom\n  goroutine 1`, "last message repeated 1 times", `panic: boom\n  goroutine 2`}, sentMessages(t, bl, sink))
+}
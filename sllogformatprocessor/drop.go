@@ -0,0 +1,93 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sllogformatprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/sllogformatprocessor"
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+// errDropped is returned for records discarded by a drop rule or the
+// min_severity of their profile, they are not reported as failures.
+var errDropped error = errors.New("Log record dropped")
+
+// dropRuleMinSeverity is the rule of records dropped by min_severity in
+// the records_dropped metric.
+const dropRuleMinSeverity = "min_severity"
+
+// compiledDropRule is the compiled form of a ConfigDropRule.
+type compiledDropRule struct {
+	name  string
+	match []*compiledCondition
+}
+
+func (c *profileCompiler) compileDropRules(idx int, rules []*ConfigDropRule) ([]*compiledDropRule, error) {
+	var compiled []*compiledDropRule
+	for ruleIdx, rule := range rules {
+		if rule == nil || len(rule.Match) == 0 {
+			return nil, fmt.Errorf("profile %d drop rule %d requires match", idx, ruleIdx)
+		}
+		cr := &compiledDropRule{name: rule.Name}
+		if cr.name == "" {
+			cr.name = strconv.Itoa(ruleIdx)
+		}
+		for _, cond := range rule.Match {
+			cc, err := c.compileCondition(idx, "drop", cond)
+			if err != nil {
+				return nil, err
+			}
+			cr.match = append(cr.match, cc)
+		}
+		compiled = append(compiled, cr)
+	}
+	return compiled, nil
+}
+
+// holds reports if all conditions of the rule hold for the record.
+func (rule *compiledDropRule) holds(p *Parser) bool {
+	for _, cond := range rule.match {
+		if cond.eval(p) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// dropRule returns the name of the first drop rule of the profile that
+// holds for the record.
+func (profile *compiledProfile) dropRule(p *Parser) (string, bool) {
+	for _, rule := range profile.drop {
+		if rule.holds(p) {
+			return rule.name, true
+		}
+	}
+	return "", false
+}
+
+// compileMinSeverity resolves the min_severity of a profile, records
+// without severity are kept.
+func compileMinSeverity(idx int, name string) (plog.SeverityNumber, error) {
+	if name == "" {
+		return plog.SeverityNumberUnspecified, nil
+	}
+	sev, err := parseSeverityName(name)
+	if err != nil {
+		return 0, fmt.Errorf("profile %d min_severity has %s", idx, err.Error())
+	}
+	return sev, nil
+}
@@ -0,0 +1,97 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sllogformatprocessor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestProfileDrop(t *testing.T) {
+	cfg := resourceConfig()
+	cfg.Profiles[0].Drop = []*ConfigDropRule{
+		{Name: "health", Match: []*ConfigCondition{
+			{Exp: &ConfigExpression{Source: "body"}, Regexp: `^GET /healthz`},
+			{Exp: &ConfigExpression{Source: "attr:status"}, Equals: "200"},
+		}},
+		{Match: []*ConfigCondition{{Exp: &ConfigExpression{Source: "attr:probe"}}}},
+	}
+	cfg.Profiles[0].MinSeverity = "info"
+	require.NoError(t, cfg.Validate())
+	sink := new(consumertest.LogsSink)
+	core, logs := observer.New(zap.InfoLevel)
+	bl, err := newBatchLogs(zap.New(core), cfg, sink)
+	require.NoError(t, err)
+	bpt, reader := newTestTelemetry(t)
+	bl.setTelemetry(bpt)
+
+	ld := resourceLogs(0)
+	lrs := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	add := func(body string, sev plog.SeverityNumber, attrs map[string]string) {
+		lr := lrs.AppendEmpty()
+		lr.Body().SetStr(body)
+		lr.SetSeverityNumber(sev)
+		for k, v := range attrs {
+			lr.Attributes().PutStr(k, v)
+		}
+	}
+	add("GET /healthz", plog.SeverityNumberInfo, map[string]string{"status": "200"})
+	add("GET /healthz", plog.SeverityNumberInfo, map[string]string{"status": "503"})
+	add("GET /ready", plog.SeverityNumberInfo, map[string]string{"probe": "kubelet"})
+	add("cache miss", plog.SeverityNumberDebug, nil)
+	add("cache miss", plog.SeverityNumberUnspecified, nil)
+	add("disk full", plog.SeverityNumberError, nil)
+	bl.add(ld)
+
+	assert.Equal(t, []string{"GET /healthz", "cache miss", "disk full"}, sentMessages(t, bl, sink))
+	assert.Equal(t, 0, logs.Len(), "dropped records are not logged")
+	assert.Equal(t, map[string]int64{"health": 1, "1": 1, "min_severity": 1}, counterValues(t, reader, "records_dropped", "rule"))
+}
+
+func TestProfileDropUnmatched(t *testing.T) {
+	cfg := resourceConfig()
+	first := cfg.Profiles[0]
+	first.ServiceGroup = &ConfigAttribute{Exp: &ConfigExpression{Source: "rattr:missing"}, Rename: "ze_deployment_name"}
+	first.Drop = []*ConfigDropRule{{Name: "all", Match: []*ConfigCondition{{Exp: &ConfigExpression{Source: "body"}}}}}
+	cfg.Profiles = append([]ConfigProfile{first}, cfg.Profiles...)
+	require.NoError(t, cfg.Validate())
+	sink := new(consumertest.LogsSink)
+	bl, err := newBatchLogs(zap.NewNop(), cfg, sink)
+	require.NoError(t, err)
+	bpt, reader := newTestTelemetry(t)
+	bl.setTelemetry(bpt)
+
+	// the first profile fails on its service group, its drop rule does not apply
+	bl.add(resourceLogs(1))
+	assert.Equal(t, []string{"GET /index.html 0"}, sentMessages(t, bl, sink))
+	assert.Empty(t, counterValues(t, reader, "records_dropped", "rule"))
+}
+
+func TestProfileDropConfig(t *testing.T) {
+	cfg := resourceConfig()
+	cfg.Profiles[0].Drop = []*ConfigDropRule{{Name: "empty"}}
+	assert.ErrorContains(t, cfg.Validate(), "profile 0 drop rule 0 requires match")
+	cfg.Profiles[0].Drop = []*ConfigDropRule{{Match: []*ConfigCondition{{Exp: &ConfigExpression{Source: "body"}, Regexp: "("}}}}
+	assert.ErrorContains(t, cfg.Validate(), "profile 0 drop has invalid regexp (")
+	cfg.Profiles[0].Drop = nil
+	cfg.Profiles[0].MinSeverity = "loud"
+	assert.ErrorContains(t, cfg.Validate(), "profile 0 min_severity has invalid severity loud")
+}
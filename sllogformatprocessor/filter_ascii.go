@@ -18,9 +18,32 @@ import (
 	"fmt"
 	"strings"
 	"unicode"
+	"unicode/utf16"
 	"unicode/utf8"
 )
 
+const (
+	CfgCharsetASCII  string = "ascii"
+	CfgCharsetUTF8   string = "utf8"
+	CfgCharsetEscape string = "escape"
+)
+
+var cfgCharsetMap map[string]int = map[string]int{
+	CfgCharsetASCII:  0,
+	CfgCharsetUTF8:   0,
+	CfgCharsetEscape: 0,
+}
+
+// charsetFilters map a charset to the filter applied to source values.
+var charsetFilters map[string]func(string) string = map[string]func(string) string{
+	"":               FilterASCII,
+	CfgCharsetASCII:  FilterASCII,
+	CfgCharsetUTF8:   FilterUTF8,
+	CfgCharsetEscape: FilterEscape,
+}
+
+// FilterASCII drops all characters that are not ASCII and escapes
+// control characters.
 func FilterASCII(in string) string {
 	var sb strings.Builder
 	for _, r := range in {
@@ -31,24 +54,66 @@ func FilterASCII(in string) string {
 			sb.WriteRune(r)
 			continue
 		}
-		switch r {
-		case '\a':
-			sb.WriteString(`\a`)
-		case '\b':
-			sb.WriteString(`\b`)
-		case '\t':
-			sb.WriteString(`\t`)
-		case '\n':
-			sb.WriteString(`\n`)
-		case '\f':
-			sb.WriteString(`\f`)
-		case '\r':
-			sb.WriteString(`\r`)
-		case '\v':
-			sb.WriteString(`\v`)
-		default:
-			sb.WriteString(fmt.Sprintf("\\%03o", int(r)))
+		writeEscaped(&sb, r)
+	}
+	return sb.String()
+}
+
+// FilterUTF8 keeps printable characters, invalid bytes are replaced with
+// U+FFFD and other characters are escaped.
+func FilterUTF8(in string) string {
+	var sb strings.Builder
+	for _, r := range in {
+		if unicode.IsPrint(r) {
+			sb.WriteRune(r)
+			continue
+		}
+		writeEscaped(&sb, r)
+	}
+	return sb.String()
+}
+
+// FilterEscape keeps printable ASCII characters and escapes all others,
+// characters that are not ASCII as \uXXXX.
+func FilterEscape(in string) string {
+	var sb strings.Builder
+	for _, r := range in {
+		if r < utf8.RuneSelf && unicode.IsPrint(r) {
+			sb.WriteRune(r)
+			continue
 		}
+		writeEscaped(&sb, r)
 	}
 	return sb.String()
 }
+
+// writeEscaped escapes a character, characters beyond the basic
+// multilingual plane as UTF-16 surrogate pairs.
+func writeEscaped(sb *strings.Builder, r rune) {
+	switch r {
+	case '\a':
+		sb.WriteString(`\a`)
+	case '\b':
+		sb.WriteString(`\b`)
+	case '\t':
+		sb.WriteString(`\t`)
+	case '\n':
+		sb.WriteString(`\n`)
+	case '\f':
+		sb.WriteString(`\f`)
+	case '\r':
+		sb.WriteString(`\r`)
+	case '\v':
+		sb.WriteString(`\v`)
+	default:
+		switch {
+		case r < utf8.RuneSelf:
+			sb.WriteString(fmt.Sprintf("\\%03o", int(r)))
+		case r > 0xFFFF:
+			r1, r2 := utf16.EncodeRune(r)
+			sb.WriteString(fmt.Sprintf("\\u%04x\\u%04x", r1, r2))
+		default:
+			sb.WriteString(fmt.Sprintf("\\u%04x", r))
+		}
+	}
+}
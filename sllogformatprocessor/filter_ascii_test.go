@@ -18,6 +18,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
 )
 
 func TestFilterASCII(t *testing.T) {
@@ -25,3 +28,73 @@ func TestFilterASCII(t *testing.T) {
 
 	assert.Equal(t, `Hello\002, \a\r`, str, "failed to filter ASCII correctly")
 }
+
+func TestCharsetFilters(t *testing.T) {
+	in := "Gr\u00fc\u00dfe\t\u6771\u4eac \xff\U0001F600\u200b"
+	testcases := []struct {
+		charset string
+		want    string
+	}{
+		{charset: CfgCharsetASCII, want: `Gre\t `},
+		{charset: CfgCharsetUTF8, want: "Gr\u00fc\u00dfe\\t\u6771\u4eac \ufffd\U0001F600\\u200b"},
+		{charset: CfgCharsetEscape, want: `Gr\u00fc\u00dfe\t\u6771\u4eac \ufffd\ud83d\ude00\u200b`},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.charset, func(t *testing.T) {
+			assert.Equal(t, tc.want, charsetFilters[tc.charset](in))
+		})
+	}
+}
+
+func TestProfileCharset(t *testing.T) {
+	cfg := &Config{
+		Charset: CfgCharsetUTF8,
+		Profiles: []ConfigProfile{
+			{
+				ServiceGroup: &ConfigAttribute{Exp: lit("default"), Rename: "ze_deployment_name"},
+				Host:         &ConfigAttribute{Exp: &ConfigExpression{Source: "rattr:host.name"}, Rename: "host"},
+				Logbasename:  &ConfigAttribute{Exp: &ConfigExpression{Source: "rattr:app"}, Rename: "logbasename", Charset: CfgCharsetASCII},
+				Labels:       []*ConfigAttribute{{Exp: &ConfigExpression{Source: "attr:user"}, Rename: "user", Charset: CfgCharsetEscape}},
+				Message:      &ConfigAttribute{Exp: &ConfigExpression{Source: "body"}},
+				Format:       CfgFormatMessage,
+			},
+		},
+	}
+	require.NoError(t, cfg.Validate())
+	m, err := newProfileMatcher(cfg)
+	require.NoError(t, err)
+
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr("host.name", "サーバー1")
+	rl.Resource().Attributes().PutStr("app", "zähler")
+	ils := rl.ScopeLogs().AppendEmpty()
+	lr := ils.LogRecords().AppendEmpty()
+	lr.Body().SetStr("Überlauf im Puffer")
+	lr.Attributes().PutStr("user", "jörg")
+
+	gen, req, err := m.MatchProfile(zap.NewNop(), rl, ils, lr)
+	require.NoError(t, err)
+	assert.Equal(t, "サーバー1", gen.Host)
+	assert.Equal(t, "zhler", req.Logbasename)
+	assert.Equal(t, `j\u00f6rg`, req.Cfgs["user"])
+	assert.Equal(t, "Überlauf im Puffer", gen.Message)
+
+	// the profile overrides the processor
+	cfg.Profiles[0].Charset = CfgCharsetASCII
+	m, err = newProfileMatcher(cfg)
+	require.NoError(t, err)
+	gen, _, err = m.MatchProfile(zap.NewNop(), rl, ils, lr)
+	require.NoError(t, err)
+	assert.Equal(t, "1", gen.Host)
+	assert.Equal(t, "berlauf im Puffer", gen.Message)
+
+	cfg.Profiles[0].Charset = "latin1"
+	assert.ErrorContains(t, cfg.Validate(), "profile 0 invalid value latin1 for charset")
+	cfg.Profiles[0].Charset = ""
+	cfg.Profiles[0].Host.Charset = "latin1"
+	assert.ErrorContains(t, cfg.Validate(), "profile 0 host has invalid charset latin1")
+	cfg.Profiles[0].Host.Charset = ""
+	cfg.Charset = "latin1"
+	assert.ErrorContains(t, cfg.Validate(), "invalid value latin1 for charset")
+}
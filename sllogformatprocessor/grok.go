@@ -0,0 +1,172 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sllogformatprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/sllogformatprocessor"
+
+import (
+	_ "embed"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// grokPatternsFile holds the built-in patterns, one NAME pattern per line.
+//
+//go:embed patterns/grok-patterns
+var grokPatternsFile string
+
+var builtinGrokPatterns = parseGrokPatterns(grokPatternsFile)
+
+// grokReference matches %{NAME}, %{NAME:field} and %{NAME:field:type}.
+var grokReference = regexp.MustCompile(`%\{(\w+)(?::([^:}]+))?(?::(\w+))?\}`)
+
+var grokName = regexp.MustCompile(`^\w+$`)
+
+func parseGrokPatterns(in string) map[string]string {
+	patterns := map[string]string{}
+	for _, line := range strings.Split(in, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, pattern, ok := strings.Cut(line, " ")
+		if ok {
+			patterns[name] = strings.TrimSpace(pattern)
+		}
+	}
+	return patterns
+}
+
+// grokLibrary expands grok patterns to regular expressions. User defined
+// patterns take precedence over the built-in ones.
+type grokLibrary struct {
+	patterns map[string]string
+	expanded map[string]string
+}
+
+func newGrokLibrary(custom map[string]string) (*grokLibrary, error) {
+	g := &grokLibrary{
+		patterns: make(map[string]string, len(builtinGrokPatterns)+len(custom)),
+		expanded: map[string]string{},
+	}
+	for name, pattern := range builtinGrokPatterns {
+		g.patterns[name] = pattern
+	}
+	for name, pattern := range custom {
+		if !grokName.MatchString(name) {
+			return nil, fmt.Errorf("grok pattern name %s invalid, expecting letters, digits and underscores", name)
+		}
+		g.patterns[name] = pattern
+	}
+	for name := range custom {
+		if _, err := g.compile("%{" + name + "}"); err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+// compile expands the pattern and compiles the resulting regular
+// expression, every named reference becomes a named group.
+func (g *grokLibrary) compile(pattern string) (*regexp.Regexp, error) {
+	expanded, err := g.expand(pattern, nil)
+	if err != nil {
+		return nil, err
+	}
+	re, err := regexp.Compile(expanded)
+	if err != nil {
+		return nil, fmt.Errorf("grok pattern %s invalid - %s", pattern, err.Error())
+	}
+	return re, nil
+}
+
+func (g *grokLibrary) expand(pattern string, stack []string) (string, error) {
+	var err error
+	ret := grokReference.ReplaceAllStringFunc(pattern, func(ref string) string {
+		if err != nil {
+			return ""
+		}
+		sub := grokReference.FindStringSubmatch(ref)
+		name, field := sub[1], grokFieldName(sub[2])
+		var inner string
+		if inner, err = g.expandName(name, stack); err != nil {
+			return ""
+		}
+		if field == "" {
+			return "(?:" + inner + ")"
+		}
+		return "(?P<" + field + ">" + inner + ")"
+	})
+	return ret, err
+}
+
+func (g *grokLibrary) expandName(name string, stack []string) (string, error) {
+	if ret, ok := g.expanded[name]; ok {
+		return ret, nil
+	}
+	for _, seen := range stack {
+		if seen == name {
+			return "", fmt.Errorf("grok pattern %s is recursive, %s", name, strings.Join(append(stack, name), " -> "))
+		}
+	}
+	pattern, ok := g.patterns[name]
+	if !ok {
+		return "", fmt.Errorf("grok pattern %s not defined", name)
+	}
+	ret, err := g.expand(pattern, append(stack, name))
+	if err != nil {
+		return "", err
+	}
+	g.expanded[name] = ret
+	return ret, nil
+}
+
+// grokFieldName turns a field such as [http][verb] or http.verb into a
+// valid group name, http_verb.
+func grokFieldName(field string) string {
+	return strings.Join(strings.FieldsFunc(field, func(c rune) bool {
+		return !unicode.IsLetter(c) && !unicode.IsDigit(c) && c != '_'
+	}), "_")
+}
+
+// grokFields concatenates all named fields of the first match of r in the input.
+func grokFields(r *regexp.Regexp, in string) string {
+	arr := r.FindStringSubmatch(in)
+	if arr == nil {
+		return ""
+	}
+	var sb strings.Builder
+	for idx, name := range r.SubexpNames() {
+		if name != "" {
+			sb.WriteString(arr[idx])
+		}
+	}
+	return sb.String()
+}
+
+// grokField returns the named field of the first match of r in the input,
+// the first non-empty group is used if the name appears several times.
+func grokField(r *regexp.Regexp, in, field string) string {
+	arr := r.FindStringSubmatch(in)
+	if arr == nil {
+		return ""
+	}
+	for idx, name := range r.SubexpNames() {
+		if name == field && arr[idx] != "" {
+			return arr[idx]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,198 @@
+package sllogformatprocessor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+func TestGrokBuiltinPatterns(t *testing.T) {
+	g, err := newGrokLibrary(nil)
+	require.NoError(t, err)
+	for name := range builtinGrokPatterns {
+		_, err := g.compile("%{" + name + "}")
+		assert.NoError(t, err, name)
+	}
+}
+
+func TestGrokLibraryCompile(t *testing.T) {
+	g, err := newGrokLibrary(map[string]string{
+		"APPID":   `[A-Z]{3}-\d+`,
+		"APPLINE": `%{APPID:app} %{LOGLEVEL:level}`,
+	})
+	require.NoError(t, err)
+
+	testcases := []struct {
+		name    string
+		pattern string
+		in      string
+		want    map[string]string
+	}{
+		{
+			name:    "combined apache log",
+			pattern: "%{COMBINEDAPACHELOG}",
+			in:      `127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326 "http://www.example.com/start.html" "Mozilla/4.08"`,
+			want: map[string]string{
+				"clientip":    "127.0.0.1",
+				"auth":        "frank",
+				"timestamp":   "10/Oct/2000:13:55:36 -0700",
+				"verb":        "GET",
+				"request":     "/apache_pb.gif",
+				"httpversion": "1.0",
+				"response":    "200",
+				"bytes":       "2326",
+				"referrer":    `"http://www.example.com/start.html"`,
+				"agent":       `"Mozilla/4.08"`,
+			},
+		},
+		{
+			name:    "syslog base",
+			pattern: "%{SYSLOGBASE} %{GREEDYDATA:message}",
+			in:      "Mar  7 04:02:16 web-01 sshd[1234]: Accepted publickey for root",
+			want: map[string]string{
+				"timestamp": "Mar  7 04:02:16",
+				"logsource": "web-01",
+				"program":   "sshd",
+				"pid":       "1234",
+				"message":   "Accepted publickey for root",
+			},
+		},
+		{
+			name:    "custom patterns and field names",
+			pattern: "%{APPLINE} %{IP:[client][ip]} %{NUMBER:took.ms:float}ms",
+			in:      "ABC-42 WARN 10.0.0.1 12.5ms",
+			want: map[string]string{
+				"app":       "ABC-42",
+				"level":     "WARN",
+				"client_ip": "10.0.0.1",
+				"took_ms":   "12.5",
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			re, err := g.compile(tc.pattern)
+			require.NoError(t, err)
+			match := re.FindStringSubmatch(tc.in)
+			require.NotNil(t, match)
+			for field, want := range tc.want {
+				assert.Equal(t, want, grokField(re, tc.in, field), field)
+			}
+		})
+	}
+}
+
+func TestGrokLibraryErrors(t *testing.T) {
+	_, err := newGrokLibrary(map[string]string{"A": "%{B}", "B": "%{A}"})
+	assert.ErrorContains(t, err, "recursive")
+	_, err = newGrokLibrary(map[string]string{"A": "%{MISSING}"})
+	assert.ErrorContains(t, err, "grok pattern MISSING not defined")
+	_, err = newGrokLibrary(map[string]string{"A-B": "x"})
+	assert.ErrorContains(t, err, "grok pattern name A-B invalid")
+	_, err = newGrokLibrary(map[string]string{"A": "(x"})
+	assert.ErrorContains(t, err, "grok pattern %{A} invalid")
+
+	// built-in patterns can be replaced
+	g, err := newGrokLibrary(map[string]string{"LOGLEVEL": "(?:OK|KO)"})
+	require.NoError(t, err)
+	re, err := g.compile("%{LOGLEVEL:level}")
+	require.NoError(t, err)
+	assert.Equal(t, "KO", grokField(re, "status KO", "level"))
+	assert.Equal(t, "", grokField(re, "status ERROR", "level"))
+}
+
+func TestGrokOperator(t *testing.T) {
+	lr := plog.NewLogRecord()
+	lr.Body().SetStr("2024-03-01T10:00:00Z ERROR [db-1] connection refused")
+	p := &Parser{Log: zap.NewNop(), Body: lr.Body(), Attr: lr.Attributes(), Record: lr}
+	body := &ConfigExpression{Source: "body"}
+	pattern := lit("%{TIMESTAMP_ISO8601:ts} %{LOGLEVEL:level} \\[%{HOSTNAME:host}\\] %{GREEDYDATA:msg}")
+
+	testcases := []struct {
+		name string
+		exp  *ConfigExpression
+		want string
+	}{
+		{name: "all fields", exp: op(CfgOpGrok, body, lit("%{LOGLEVEL:level} \\[%{HOSTNAME:host}\\]")), want: "ERRORdb-1"},
+		{name: "field", exp: op(CfgOpGrok, body, pattern, lit("level")), want: "ERROR"},
+		{name: "field host", exp: op(CfgOpGrok, body, pattern, lit("host")), want: "db-1"},
+		{name: "field msg", exp: op(CfgOpGrok, body, pattern, lit("msg")), want: "connection refused"},
+		{name: "no match", exp: op(CfgOpGrok, body, lit("%{IPV4:ip}")), want: ""},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			exp, err := testCompiler(t).compileExp(tc.exp)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, exp.eval(p))
+		})
+	}
+
+	for _, exp := range []*ConfigExpression{
+		op(CfgOpGrok, body, body),
+		op(CfgOpGrok, body, pattern, lit("missing")),
+		op(CfgOpGrok, body, pattern, body),
+		op(CfgOpGrok, body, lit("%{UNDEFINED}")),
+		op(CfgOpGrok, body),
+		op(CfgOpGrok, body, pattern, lit("level"), lit("host")),
+	} {
+		_, err := testCompiler(t).compileExp(exp)
+		assert.Error(t, err)
+	}
+}
+
+func TestExtractGrok(t *testing.T) {
+	cfg := &Config{
+		GrokPatterns: map[string]string{
+			"APPLOG": `%{TIMESTAMP_ISO8601:ts} %{LOGLEVEL:level} \[%{HOSTNAME:node}\] %{GREEDYDATA:msg}`,
+		},
+		Profiles: []ConfigProfile{
+			{
+				Extract: []*ConfigExtract{
+					{Exp: &ConfigExpression{Source: "body"}, Grok: "%{APPLOG}"},
+				},
+				ServiceGroup: &ConfigAttribute{Exp: lit("default"), Rename: "ze_deployment_name"},
+				Host:         &ConfigAttribute{Exp: &ConfigExpression{Source: "var:node"}, Rename: "host"},
+				Logbasename:  &ConfigAttribute{Exp: lit("app"), Rename: "logbasename"},
+				Severity:     &ConfigAttribute{Exp: &ConfigExpression{Source: "var:level"}},
+				Message:      &ConfigAttribute{Exp: &ConfigExpression{Source: "var:msg"}},
+			},
+		},
+	}
+	require.NoError(t, cfg.Validate())
+	m, err := newProfileMatcher(cfg)
+	require.NoError(t, err)
+
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	ils := rl.ScopeLogs().AppendEmpty()
+	lr := ils.LogRecords().AppendEmpty()
+	lr.Body().SetStr("2024-03-01T10:00:00Z WARN [db-1] slow query")
+
+	gen, req, err := m.MatchProfile(zap.NewNop(), rl, ils, lr)
+	require.NoError(t, err)
+	assert.Equal(t, "db-1", gen.Host)
+	assert.Equal(t, plog.SeverityNumberWarn, lr.SeverityNumber())
+	assert.Equal(t, "slow query", gen.Message)
+	assert.Equal(t, "2024-03-01T10:00:00Z", req.Cfgs["ts"])
+
+	// the groups remain var sources without becoming labels
+	labels := false
+	cfg.Profiles[0].Extract[0].Labels = &labels
+	m, err = newProfileMatcher(cfg)
+	require.NoError(t, err)
+	gen, req, err = m.MatchProfile(zap.NewNop(), rl, ils, lr)
+	require.NoError(t, err)
+	assert.Equal(t, "db-1", gen.Host)
+	assert.Equal(t, "slow query", gen.Message)
+	assert.NotContains(t, req.Cfgs, "ts")
+	assert.NotContains(t, req.Cfgs, "msg")
+
+	cfg.Profiles[0].Extract[0].Regexp = "(?P<x>.*)"
+	assert.ErrorContains(t, cfg.Validate(), "exactly one of regexp or grok")
+	cfg.Profiles[0].Extract[0].Regexp = ""
+	cfg.Profiles[0].Extract[0].Grok = "%{NOTDEFINED}"
+	assert.ErrorContains(t, cfg.Validate(), "grok pattern NOTDEFINED not defined")
+}
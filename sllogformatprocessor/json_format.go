@@ -0,0 +1,173 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sllogformatprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/sllogformatprocessor"
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+const (
+	CfgJSONTimestamp string = "timestamp"
+	CfgJSONSeverity  string = "severity"
+	CfgJSONMessage   string = "message"
+	CfgJSONTraceID   string = "trace_id"
+	CfgJSONSpanID    string = "span_id"
+
+	defaultJSONConflictPrefix = "body_"
+)
+
+var cfgJSONFieldMap map[string]int = map[string]int{
+	CfgJSONTimestamp: 0,
+	CfgJSONSeverity:  0,
+	CfgJSONMessage:   0,
+	CfgJSONTraceID:   0,
+	CfgJSONSpanID:    0,
+}
+
+// defaultJSONFields are the fields of the json format if none are
+// configured.
+var defaultJSONFields = []string{CfgJSONTimestamp, CfgJSONSeverity, CfgJSONMessage, CfgJSONTraceID, CfgJSONSpanID}
+
+// compiledJSON is the compiled form of a ConfigJSON.
+type compiledJSON struct {
+	fields         []string
+	attrs          []*compiledAttr
+	conflictPrefix string
+}
+
+func (c *profileCompiler) compileJSON(idx int, cfg *ConfigJSON) (*compiledJSON, error) {
+	cj := &compiledJSON{fields: defaultJSONFields, conflictPrefix: defaultJSONConflictPrefix}
+	if cfg == nil {
+		return cj, nil
+	}
+	if len(cfg.Fields) > 0 {
+		cj.fields = nil
+		for _, field := range cfg.Fields {
+			if err := validateCfgString(idx, "json fields", field, cfgJSONFieldMap); err != nil {
+				return nil, err
+			}
+			cj.fields = append(cj.fields, field)
+		}
+	}
+	if cfg.ConflictPrefix != "" {
+		cj.conflictPrefix = cfg.ConflictPrefix
+	}
+	for _, attr := range cfg.Attributes {
+		ca, err := c.compileAttr(idx, "json attributes", attr)
+		if err != nil {
+			return nil, err
+		}
+		cj.attrs = append(cj.attrs, ca)
+	}
+	return cj, nil
+}
+
+// evalAttrs returns the attributes that are not empty.
+func (cj *compiledJSON) evalAttrs(p *Parser) map[string]string {
+	fields := map[string]string{}
+	for _, attr := range cj.attrs {
+		if id, value := attr.eval(p); value != "" {
+			fields[id] = value
+		}
+	}
+	return fields
+}
+
+// format builds a JSON object from the fields of the log record. A
+// message holding a JSON object is merged into it, keys of the message
+// that conflict with a field are kept under the conflict prefix. Keys
+// are sorted.
+func (cj *compiledJSON) format(gen *ConfigResult, lr plog.LogRecord) string {
+	generated := map[string]any{}
+	for key, value := range gen.fields {
+		generated[key] = value
+	}
+	doc := map[string]any{}
+	for _, field := range cj.fields {
+		switch field {
+		case CfgJSONTimestamp:
+			ts := lr.Timestamp()
+			if ts == 0 {
+				ts = lr.ObservedTimestamp()
+			}
+			generated[field] = ts.AsTime().UTC().Format(time.RFC3339Nano)
+		case CfgJSONSeverity:
+			generated[field] = severityMap[lr.SeverityNumber()]
+		case CfgJSONMessage:
+			if obj, ok := decodeJSONObject(gen.Message); ok {
+				doc = obj
+			} else {
+				generated[field] = gen.Message
+			}
+		case CfgJSONTraceID:
+			if !lr.TraceID().IsEmpty() {
+				generated[field] = lr.TraceID().String()
+			}
+		case CfgJSONSpanID:
+			if !lr.SpanID().IsEmpty() {
+				generated[field] = lr.SpanID().String()
+			}
+		}
+	}
+	keys := make([]string, 0, len(generated))
+	for key := range generated {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if existing, ok := doc[key]; ok {
+			renamed := cj.conflictPrefix + key
+			for {
+				_, inDoc := doc[renamed]
+				_, inGenerated := generated[renamed]
+				if !inDoc && !inGenerated {
+					break
+				}
+				renamed = cj.conflictPrefix + renamed
+			}
+			doc[renamed] = existing
+		}
+		doc[key] = generated[key]
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(doc); err != nil {
+		// Values are strings or decoded JSON, this does not happen
+		return gen.Message
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// decodeJSONObject decodes a message holding a JSON object, numbers keep
+// their precision.
+func decodeJSONObject(msg string) (map[string]any, bool) {
+	if !strings.HasPrefix(msg, "{") {
+		return nil, false
+	}
+	dec := json.NewDecoder(strings.NewReader(msg))
+	dec.UseNumber()
+	obj := map[string]any{}
+	if err := dec.Decode(&obj); err != nil || dec.More() {
+		return nil, false
+	}
+	return obj, true
+}
@@ -0,0 +1,103 @@
+package sllogformatprocessor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+func TestJSONFormat(t *testing.T) {
+	cfg := &Config{
+		Profiles: []ConfigProfile{
+			{
+				ServiceGroup: &ConfigAttribute{Exp: lit("default"), Rename: "ze_deployment_name"},
+				Host:         &ConfigAttribute{Exp: lit("host1"), Rename: "host"},
+				Logbasename:  &ConfigAttribute{Exp: lit("app"), Rename: "logbasename"},
+				Message:      &ConfigAttribute{Exp: &ConfigExpression{Source: "body"}},
+				Format:       CfgFormatJSON,
+				JSON: &ConfigJSON{
+					Attributes: []*ConfigAttribute{
+						{Exp: &ConfigExpression{Source: "attr:http.method"}, Rename: "method"},
+						{Exp: &ConfigExpression{Source: "attr:user"}},
+						{Exp: &ConfigExpression{Source: "attr:missing"}},
+					},
+				},
+			},
+		},
+	}
+	require.NoError(t, cfg.Validate())
+	m, err := newProfileMatcher(cfg)
+	require.NoError(t, err)
+
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	ils := rl.ScopeLogs().AppendEmpty()
+	lr := ils.LogRecords().AppendEmpty()
+	lr.SetTimestamp(pcommon.Timestamp(1709287200123000000))
+	lr.SetSeverityNumber(plog.SeverityNumberWarn)
+	lr.Attributes().PutStr("http.method", "GET")
+	lr.Attributes().PutStr("user", "alice")
+
+	testcases := []struct {
+		name string
+		body func(v pcommon.Value)
+		want string
+	}{
+		{
+			name: "text",
+			body: func(v pcommon.Value) { v.SetStr(`disk "sda" <full> & more`) },
+			want: `{"message":"disk \"sda\" <full> & more","method":"GET","severity":"WARN","timestamp":"2024-03-01T10:00:00.123Z","user":"alice"}`,
+		},
+		{
+			name: "object merged with conflicts",
+			body: func(v pcommon.Value) {
+				v.SetStr(`{"severity":"debug","body_severity":1,"msg":"ok","n":12345678901234567890,"z":{"b":1,"a":2}}`)
+			},
+			want: `{"body_body_severity":"debug","body_severity":1,"method":"GET","msg":"ok","n":12345678901234567890,"severity":"WARN","timestamp":"2024-03-01T10:00:00.123Z","user":"alice","z":{"a":2,"b":1}}`,
+		},
+		{
+			name: "map body",
+			body: func(v pcommon.Value) { v.SetEmptyMap().PutStr("msg", "ok") },
+			want: `{"method":"GET","msg":"ok","severity":"WARN","timestamp":"2024-03-01T10:00:00.123Z","user":"alice"}`,
+		},
+		{
+			name: "not an object",
+			body: func(v pcommon.Value) { v.SetStr(`{not json}`) },
+			want: `{"message":"{not json}","method":"GET","severity":"WARN","timestamp":"2024-03-01T10:00:00.123Z","user":"alice"}`,
+		},
+		{
+			name: "array",
+			body: func(v pcommon.Value) { v.SetStr(`{"a":1} {"b":2}`) },
+			want: `{"message":"{\"a\":1} {\"b\":2}","method":"GET","severity":"WARN","timestamp":"2024-03-01T10:00:00.123Z","user":"alice"}`,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.body(lr.Body())
+			gen, _, err := m.MatchProfile(zap.NewNop(), rl, ils, lr)
+			require.NoError(t, err)
+			assert.Equal(t, CfgFormatJSON, gen.Format)
+			assert.Equal(t, tc.want, gen.Message)
+		})
+	}
+
+	// selected fields with trace context
+	cfg.Profiles[0].JSON = &ConfigJSON{Fields: []string{CfgJSONMessage, CfgJSONTraceID, CfgJSONSpanID}, ConflictPrefix: "orig."}
+	m, err = newProfileMatcher(cfg)
+	require.NoError(t, err)
+	lr.SetTraceID(pcommon.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16})
+	lr.SetSpanID(pcommon.SpanID{1, 2, 3, 4, 5, 6, 7, 8})
+	lr.Body().SetStr(`{"trace_id":"x"}`)
+	gen, _, err := m.MatchProfile(zap.NewNop(), rl, ils, lr)
+	require.NoError(t, err)
+	assert.Equal(t, `{"orig.trace_id":"x","span_id":"0102030405060708","trace_id":"0102030405060708090a0b0c0d0e0f10"}`, gen.Message)
+
+	cfg.Profiles[0].JSON = &ConfigJSON{Fields: []string{"level"}}
+	assert.ErrorContains(t, cfg.Validate(), "profile 0 invalid value level for json fields")
+	cfg.Profiles[0].JSON = &ConfigJSON{Attributes: []*ConfigAttribute{{Exp: &ConfigExpression{Source: "attr:a", Op: CfgOpLc}}}}
+	assert.ErrorContains(t, cfg.Validate(), "profile 0 invalid, must specify exactly one of source or operator")
+}
@@ -0,0 +1,227 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sllogformatprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/sllogformatprocessor"
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+type pathSegmentKind int
+
+const (
+	// segKey is a plain key, consecutive plain keys are also looked up
+	// joined with dots so that keys containing dots keep working.
+	segKey pathSegmentKind = iota
+	// segQuotedKey is a key in brackets and quotes, matched exactly.
+	segQuotedKey
+	// segIndex is a slice index, negative values count from the end.
+	segIndex
+	// segWildcard selects all elements of a slice or map.
+	segWildcard
+)
+
+type pathSegment struct {
+	kind  pathSegmentKind
+	key   string
+	index int
+}
+
+// keyPath is a parsed key path such as items[0].name, tags[*] or
+// ["log.file.path"].
+type keyPath []pathSegment
+
+// parseKeyPath parses the key path of a source. Plain keys are separated
+// by dots and may escape dots and brackets with a backslash. Brackets hold
+// an index, a quoted key or the * wildcard.
+func parseKeyPath(in string) (keyPath, error) {
+	var path keyPath
+	expectKey := true
+	for i := 0; i < len(in); {
+		switch {
+		case in[i] == '[':
+			end, seg, err := parseBracket(in, i)
+			if err != nil {
+				return nil, err
+			}
+			path = append(path, seg)
+			i = end
+			expectKey = false
+		case in[i] == '.' && !expectKey:
+			if i+1 >= len(in) {
+				return nil, fmt.Errorf("key path %s ends with a dot", in)
+			}
+			i++
+			expectKey = true
+		case expectKey:
+			var sb strings.Builder
+			for ; i < len(in) && in[i] != '.' && in[i] != '['; i++ {
+				if in[i] == '\\' && i+1 < len(in) {
+					i++
+				}
+				sb.WriteByte(in[i])
+			}
+			if sb.Len() == 0 {
+				return nil, fmt.Errorf("key path %s has an empty key", in)
+			}
+			path = append(path, pathSegment{kind: segKey, key: sb.String()})
+			expectKey = false
+		default:
+			return nil, fmt.Errorf("key path %s expecting . or [ at offset %d", in, i)
+		}
+	}
+	return path, nil
+}
+
+// parseBracket parses the bracket starting at in[start] and returns the
+// offset after the closing bracket.
+func parseBracket(in string, start int) (int, pathSegment, error) {
+	i := start + 1
+	if i < len(in) && (in[i] == '"' || in[i] == '\'') {
+		quote := in[i]
+		var sb strings.Builder
+		for i++; i < len(in) && in[i] != quote; i++ {
+			if in[i] == '\\' && i+1 < len(in) {
+				i++
+			}
+			sb.WriteByte(in[i])
+		}
+		if i+1 >= len(in) || in[i+1] != ']' {
+			return 0, pathSegment{}, fmt.Errorf("key path %s has an unterminated quoted key at offset %d", in, start)
+		}
+		return i + 2, pathSegment{kind: segQuotedKey, key: sb.String()}, nil
+	}
+	end := strings.IndexByte(in[i:], ']')
+	if end < 0 {
+		return 0, pathSegment{}, fmt.Errorf("key path %s has an unterminated bracket at offset %d", in, start)
+	}
+	content := in[i : i+end]
+	if content == "*" {
+		return i + end + 1, pathSegment{kind: segWildcard}, nil
+	}
+	index, err := strconv.Atoi(content)
+	if err != nil {
+		return 0, pathSegment{}, fmt.Errorf("key path %s has an invalid index %s, expecting a number, * or a quoted key", in, content)
+	}
+	return i + end + 1, pathSegment{kind: segIndex, index: index}, nil
+}
+
+// lookupMap returns all values selected by the path starting from a map.
+func (kp keyPath) lookupMap(in pcommon.Map, out []pcommon.Value) []pcommon.Value {
+	if len(kp) == 0 {
+		return out
+	}
+	seg := kp[0]
+	switch seg.kind {
+	case segWildcard:
+		in.Range(func(_ string, val pcommon.Value) bool {
+			out = kp[1:].lookup(val, out)
+			return true
+		})
+	case segQuotedKey:
+		if val, ok := in.Get(seg.key); ok {
+			out = kp[1:].lookup(val, out)
+		}
+	case segKey:
+		// Look up consecutive plain keys joined with dots, shortest
+		// first, until the rest of the path matches
+		key := seg.key
+		var intermediate *pcommon.Value
+		for idx := 0; ; idx++ {
+			if val, ok := in.Get(key); ok {
+				if vals := kp[idx+1:].lookup(val, out); len(vals) > len(out) {
+					return vals
+				}
+				if intermediate == nil && val.Type() != pcommon.ValueTypeMap && kp[idx+1:].plain() {
+					intermediate = &val
+				}
+			}
+			if idx+1 >= len(kp) || kp[idx+1].kind != segKey {
+				break
+			}
+			key += "." + kp[idx+1].key
+		}
+		// A plain dotted path through a value that is not a map
+		// selects that value, as it did before key paths supported
+		// indexes.
+		if intermediate != nil {
+			return append(out, *intermediate)
+		}
+	}
+	return out
+}
+
+// plain reports if the path only holds plain keys, such as a.b.c.
+func (kp keyPath) plain() bool {
+	for _, seg := range kp {
+		if seg.kind != segKey {
+			return false
+		}
+	}
+	return true
+}
+
+// lookup returns all values selected by the path starting from a value.
+func (kp keyPath) lookup(val pcommon.Value, out []pcommon.Value) []pcommon.Value {
+	if len(kp) == 0 {
+		return append(out, val)
+	}
+	switch val.Type() {
+	case pcommon.ValueTypeMap:
+		if kp[0].kind != segIndex {
+			return kp.lookupMap(val.Map(), out)
+		}
+	case pcommon.ValueTypeSlice:
+		slice := val.Slice()
+		switch kp[0].kind {
+		case segWildcard:
+			for idx := 0; idx < slice.Len(); idx++ {
+				out = kp[1:].lookup(slice.At(idx), out)
+			}
+		case segIndex:
+			idx := kp[0].index
+			if idx < 0 {
+				idx += slice.Len()
+			}
+			if idx >= 0 && idx < slice.Len() {
+				out = kp[1:].lookup(slice.At(idx), out)
+			}
+		}
+	}
+	return out
+}
+
+// evalValues formats the selected values, multiple values from wildcards
+// are separated by spaces.
+func evalValues(vals []pcommon.Value) string {
+	switch len(vals) {
+	case 0:
+		return ""
+	case 1:
+		return evalValue("", vals[0])
+	}
+	strs := make([]string, len(vals))
+	for idx, val := range vals {
+		strs[idx] = evalValue("", val)
+	}
+	return strings.Join(strs, " ")
+}
+
+func evalMap(path keyPath, in pcommon.Map) string {
+	return evalValues(path.lookupMap(in, nil))
+}
@@ -0,0 +1,120 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sllogformatprocessor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+func TestParseKeyPath(t *testing.T) {
+	testCases := []struct {
+		path     string
+		expected keyPath
+		err      bool
+	}{
+		{path: "", expected: nil},
+		{path: "host", expected: keyPath{{kind: segKey, key: "host"}}},
+		{path: "log.file.path", expected: keyPath{
+			{kind: segKey, key: "log"}, {kind: segKey, key: "file"}, {kind: segKey, key: "path"}}},
+		{path: `log\.file\.path`, expected: keyPath{{kind: segKey, key: "log.file.path"}}},
+		{path: "items[0].name", expected: keyPath{
+			{kind: segKey, key: "items"}, {kind: segIndex, index: 0}, {kind: segKey, key: "name"}}},
+		{path: "items[-1]", expected: keyPath{{kind: segKey, key: "items"}, {kind: segIndex, index: -1}}},
+		{path: "tags[*]", expected: keyPath{{kind: segKey, key: "tags"}, {kind: segWildcard}}},
+		{path: `["log.file.path"]`, expected: keyPath{{kind: segQuotedKey, key: "log.file.path"}}},
+		{path: `event['a]b'][1][*].x`, expected: keyPath{
+			{kind: segKey, key: "event"}, {kind: segQuotedKey, key: "a]b"}, {kind: segIndex, index: 1},
+			{kind: segWildcard}, {kind: segKey, key: "x"}}},
+		{path: `["say \"hi\""]`, expected: keyPath{{kind: segQuotedKey, key: `say "hi"`}}},
+		{path: "items[", err: true},
+		{path: "items[x]", err: true},
+		{path: `items["x]`, err: true},
+		{path: "items.", err: true},
+		{path: "a..b", err: true},
+		{path: ".a", err: true},
+		{path: "items[0]name", err: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			path, err := parseKeyPath(tc.path)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, path)
+		})
+	}
+}
+
+func TestEvalMapKeyPath(t *testing.T) {
+	m := pcommon.NewMap()
+	require.NoError(t, m.FromRaw(map[string]any{
+		"log.file.path": "/var/log/pods/app.log",
+		"log": map[string]any{
+			"level": "warn",
+		},
+		"event": map[string]any{
+			"items": []any{
+				map[string]any{"name": "first", "id": 1},
+				map[string]any{"name": "second", "id": 2},
+				map[string]any{"name": "third", "id": 3},
+			},
+			"tags": []any{"a", "b", "c"},
+		},
+		"k8s": map[string]any{
+			"pod.name": "nginx-1",
+		},
+		"message": "hello",
+	}))
+	testCases := []struct {
+		path     string
+		expected string
+	}{
+		{"log.file.path", "/var/log/pods/app.log"},
+		{`["log.file.path"]`, "/var/log/pods/app.log"},
+		{"log.level", "warn"},
+		{`["log"].level`, "warn"},
+		{"k8s.pod.name", "nginx-1"},
+		{`k8s["pod.name"]`, "nginx-1"},
+		{"event.items[0].name", "first"},
+		{"event.items[-1].name", "third"},
+		{"event.items[3].name", ""},
+		{"event.items[-4].name", ""},
+		{"event.items[*].id", "1 2 3"},
+		{"event.tags", "a b c"},
+		{"event.tags[*]", "a b c"},
+		{"event.tags[1]", "b"},
+		{"event.tags[0].x", ""},
+		{"message.x", "hello"},
+		{"message.x.y", "hello"},
+		{"event.tags.x", "a b c"},
+		{`message["x"]`, ""},
+		{"message[0]", ""},
+		{"missing", ""},
+		{"", ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			path, err := parseKeyPath(tc.path)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, evalMap(path, m))
+		})
+	}
+}
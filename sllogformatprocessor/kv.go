@@ -0,0 +1,167 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sllogformatprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/sllogformatprocessor"
+
+import (
+	"encoding/json"
+	"strings"
+	"unicode"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+const (
+	// kvDefaultSep separates keys from values in logfmt.
+	kvDefaultSep = "="
+)
+
+// parseKV parses key<sep>value pairs such as level=info msg="a b" user=42.
+// Pairs are separated by white space, or by any of the characters in
+// delims if set. Values may be quoted with double or single quotes and
+// escape quotes with a backslash. The first occurrence of a key wins and
+// keys without separator have an empty value. Input holding a JSON
+// object, such as a map formatted by evalValue, is decoded instead.
+func parseKV(in, sep, delims string) map[string]string {
+	ret := map[string]string{}
+	if parseKVJSON(in, ret) {
+		return ret
+	}
+	return parseKVPairs(in, sep, delims, ret)
+}
+
+// parseKVPairs adds the key<sep>value pairs of in to ret, white space
+// around the separator is ignored.
+func parseKVPairs(in, sep, delims string, ret map[string]string) map[string]string {
+	isBlank := func(c byte) bool { return c == ' ' || c == '\t' }
+	isDelim := func(c byte) bool {
+		if delims == "" {
+			return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+		}
+		return strings.IndexByte(delims, c) >= 0
+	}
+	for i := 0; i < len(in); {
+		if isDelim(in[i]) {
+			i++
+			continue
+		}
+		start := i
+		for i < len(in) && !isDelim(in[i]) && !strings.HasPrefix(in[i:], sep) {
+			i++
+		}
+		key := strings.TrimSpace(in[start:i])
+		// white space delimits pairs unless a separator follows it,
+		// such as in key = value
+		spaced := false
+		if key != "" && i < len(in) && isBlank(in[i]) && isDelim(in[i]) {
+			j := i
+			for j < len(in) && isBlank(in[j]) {
+				j++
+			}
+			if strings.HasPrefix(in[j:], sep) {
+				i, spaced = j, true
+			}
+		}
+		if i >= len(in) || isDelim(in[i]) {
+			// A key without value
+			if _, ok := ret[key]; !ok && key != "" {
+				ret[key] = ""
+			}
+			continue
+		}
+		i += len(sep)
+		for i < len(in) && isBlank(in[i]) && (spaced || !isDelim(in[i])) {
+			i++
+		}
+		var value string
+		if i < len(in) && (in[i] == '"' || in[i] == '\'') {
+			value, i = parseKVQuoted(in, i)
+		} else {
+			start = i
+			for i < len(in) && !isDelim(in[i]) {
+				i++
+			}
+			value = strings.TrimSpace(in[start:i])
+		}
+		if _, ok := ret[key]; !ok && key != "" {
+			ret[key] = value
+		}
+	}
+	return ret
+}
+
+// parseKVQuoted returns the unescaped value of the string quoted at
+// in[start] and the offset after the closing quote. An unterminated
+// quote extends to the end of the input.
+func parseKVQuoted(in string, start int) (string, int) {
+	quote := in[start]
+	var sb strings.Builder
+	i := start + 1
+	for ; i < len(in) && in[i] != quote; i++ {
+		if in[i] == '\\' && i+1 < len(in) {
+			// Only quotes and backslashes are unescaped, escaped
+			// control characters must stay escaped in the output.
+			if in[i+1] == quote || in[i+1] == '\\' {
+				i++
+			}
+		}
+		sb.WriteByte(in[i])
+	}
+	if i < len(in) {
+		i++
+	}
+	return sb.String(), i
+}
+
+func parseKVJSON(in string, out map[string]string) bool {
+	in = strings.TrimLeftFunc(in, unicode.IsSpace)
+	if !strings.HasPrefix(in, "{") {
+		return false
+	}
+	raw := map[string]any{}
+	if err := json.Unmarshal([]byte(in), &raw); err != nil {
+		return false
+	}
+	m := pcommon.NewMap()
+	if err := m.FromRaw(raw); err != nil {
+		return false
+	}
+	kvFromMap(m, out)
+	return true
+}
+
+func kvFromMap(m pcommon.Map, out map[string]string) {
+	m.Range(func(k string, v pcommon.Value) bool {
+		out[k] = evalValue("", v)
+		return true
+	})
+}
+
+// evalKV returns the value of key in the body parsed as key value pairs,
+// a body holding a map or a JSON object is used as is. The body is
+// parsed at most once per record, a JSON object is decoded by ParseBody.
+func (p *Parser) evalKV(key string) string {
+	if p.kvMap == nil {
+		p.kvMap = map[string]string{}
+		if body, err := p.ParseBody(); err == nil {
+			kvFromMap(body, p.kvMap)
+		} else if p.Body.Type() == pcommon.ValueTypeStr {
+			// ParseBody found no JSON object
+			parseKVPairs(evalValue("", p.Body), kvDefaultSep, "", p.kvMap)
+		} else {
+			p.kvMap = parseKV(evalValue("", p.Body), kvDefaultSep, "")
+		}
+	}
+	return p.kvMap[key]
+}
@@ -0,0 +1,140 @@
+package sllogformatprocessor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+func TestParseKV(t *testing.T) {
+	testcases := []struct {
+		name   string
+		in     string
+		sep    string
+		delims string
+		want   map[string]string
+	}{
+		{
+			name: "logfmt",
+			in:   `level=info msg="user logged in" user=42 path=/login`,
+			sep:  "=",
+			want: map[string]string{"level": "info", "msg": "user logged in", "user": "42", "path": "/login"},
+		},
+		{
+			name: "escaped quotes",
+			in:   `msg="say \"hi\" \\ bye" err='it\'s\n' empty=""`,
+			sep:  "=",
+			want: map[string]string{"msg": `say "hi" \ bye`, "err": `it's\n`, "empty": ""},
+		},
+		{
+			name: "bare keys and first wins",
+			in:   `debug level=warn level=error  trailing=`,
+			sep:  "=",
+			want: map[string]string{"debug": "", "level": "warn", "trailing": ""},
+		},
+		{
+			name:   "custom separators",
+			in:     `Account Name: admin; Logon Type: 3;Source: "10.0.0.1; x"`,
+			sep:    ":",
+			delims: ";",
+			want:   map[string]string{"Account Name": "admin", "Logon Type": "3", "Source": "10.0.0.1; x"},
+		},
+		{
+			name: "multi character separator",
+			in:   `a=>1 b=>x=y`,
+			sep:  "=>",
+			want: map[string]string{"a": "1", "b": "x=y"},
+		},
+		{
+			name: "json object",
+			in:   `{"SubjectUserName":"admin","LogonType":3,"Data":{"a":1}}`,
+			sep:  "=",
+			want: map[string]string{"SubjectUserName": "admin", "LogonType": "3", "Data": `{"a":1}`},
+		},
+		{
+			name: "spaces around separator",
+			in:   `key = value  msg =  "a b" a=1 b= c flag`,
+			sep:  "=",
+			want: map[string]string{"key": "value", "msg": "a b", "a": "1", "b": "", "c": "", "flag": ""},
+		},
+		{
+			name:   "spaces around custom separator",
+			in:     `Account Name : admin; Logon Type :3`,
+			sep:    ":",
+			delims: ";",
+			want:   map[string]string{"Account Name": "admin", "Logon Type": "3"},
+		},
+		{
+			name: "unterminated quote",
+			in:   `msg="no end`,
+			sep:  "=",
+			want: map[string]string{"msg": "no end"},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, parseKV(tc.in, tc.sep, tc.delims))
+		})
+	}
+}
+
+func TestKVOperatorAndSource(t *testing.T) {
+	lr := plog.NewLogRecord()
+	lr.Body().SetStr(`ts=2024-03-01T10:00:00Z level=warn msg="disk almost full" pct=93`)
+	eventData := lr.Attributes().PutEmptyMap("event_data")
+	eventData.PutStr("SubjectUserName", "admin")
+	eventData.PutInt("LogonType", 3)
+	lr.Attributes().PutStr("line", "user:alice,role:admin")
+	p := &Parser{Log: zap.NewNop(), Body: lr.Body(), Attr: lr.Attributes(), Record: lr}
+
+	testcases := []struct {
+		name string
+		exp  *ConfigExpression
+		want string
+	}{
+		{name: "op logfmt", exp: op(CfgOpKV, &ConfigExpression{Source: "body"}, lit("msg")), want: "disk almost full"},
+		{name: "op missing key", exp: op(CfgOpKV, &ConfigExpression{Source: "body"}, lit("host")), want: ""},
+		{name: "op map attribute", exp: op(CfgOpKV, &ConfigExpression{Source: "attr:event_data"}, lit("LogonType")), want: "3"},
+		{name: "op separators", exp: op(CfgOpKV, &ConfigExpression{Source: "attr:line"}, lit("role"), lit(":"), lit(",")), want: "admin"},
+		{name: "source", exp: &ConfigExpression{Source: "kv:level"}, want: "warn"},
+		{name: "source quoted", exp: &ConfigExpression{Source: "kv:msg"}, want: "disk almost full"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			exp, err := testCompiler(t).compileExp(tc.exp)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, exp.eval(p))
+		})
+	}
+
+	// a JSON body is decoded once for all sources
+	lr.Body().SetStr(`{"level":"info","msg":"started"}`)
+	p = &Parser{Log: zap.NewNop(), Body: lr.Body(), Attr: lr.Attributes(), Record: lr}
+	exp, err := compileSource("kv:level")
+	require.NoError(t, err)
+	assert.Equal(t, "info", exp.eval(p))
+	require.True(t, p.bodyParsed)
+	msg, err := compileSource("body:msg")
+	require.NoError(t, err)
+	assert.Equal(t, "started", msg.eval(p))
+
+	// a map body is used as is
+	lr.Body().SetEmptyMap().PutStr("level", "error")
+	p = &Parser{Log: zap.NewNop(), Body: lr.Body(), Attr: lr.Attributes(), Record: lr}
+	assert.Equal(t, "error", exp.eval(p))
+
+	for _, exp := range []*ConfigExpression{
+		{Source: "kv"},
+		op(CfgOpKV, &ConfigExpression{Source: "body"}),
+		op(CfgOpKV, &ConfigExpression{Source: "body"}, lit("a"), lit("")),
+		op(CfgOpKV, &ConfigExpression{Source: "body"}, lit("a"), &ConfigExpression{Source: "body"}),
+		op(CfgOpKV, &ConfigExpression{Source: "body"}, lit("a"), lit("="), &ConfigExpression{Source: "body"}),
+		op(CfgOpKV, &ConfigExpression{Source: "body"}, lit("a"), lit("="), lit(","), lit("x")),
+	} {
+		_, err := testCompiler(t).compileExp(exp)
+		assert.Error(t, err)
+	}
+}
@@ -17,7 +17,6 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -203,180 +202,113 @@ func evalValue(component string, val pcommon.Value) string {
 	return ret
 }
 
-func evalMap(elem string, in pcommon.Map) string {
-	arr := strings.Split(elem, ".")
-	if len(arr) < 1 {
-		return ""
-	}
-	path := ""
-	var val pcommon.Value
-	var ok bool
-	for idx, key := range arr {
-		if path == "" {
-			path = key
-		} else {
-			path += "." + key
-		}
-		val, ok = in.Get(path)
-		if ok {
-			if val.Type() == pcommon.ValueTypeMap {
-				in = val.Map()
-				path = ""
-				continue
-			}
-			if len(arr) > idx+1 {
-				elem = arr[idx+1]
-			}
-			return evalValue(elem, val)
-		}
-	}
-	if path == "" {
-		return val.AsString()
-	}
-	return ""
-}
-
 type Parser struct {
-	Log   *zap.Logger
-	Rattr pcommon.Map
-	Attr  pcommon.Map
-	Body  pcommon.Value
+	Log    *zap.Logger
+	Rattr  pcommon.Map
+	Attr   pcommon.Map
+	Body   pcommon.Value
+	Scope  pcommon.InstrumentationScope
+	Record plog.LogRecord
+	res    *resourceCache
+	// Named groups extracted by the current profile
+	vars map[string]string
+	// The body decoded as a JSON object, parsed at most once per record
+	bodyParsed bool
+	bodyMap    pcommon.Map
+	bodyErr    error
+	// The body parsed as key value pairs for the kv source
+	kvMap map[string]string
+	// The body decoded as syslog message for the syslog source
+	syslogParsed bool
+	syslog       *syslogMessage
 }
 
-func (p *Parser) evalExp(exp *ConfigExpression) (string, string) {
-	if exp == nil {
-		return "", ""
+// ParseBody returns the body as a map, decoding a string body as a JSON
+// object on first use. The error reports why a string body could not be
+// decoded, so that a missing key can be told apart from a body that is not JSON.
+func (p *Parser) ParseBody() (pcommon.Map, error) {
+	if p.bodyParsed {
+		return p.bodyMap, p.bodyErr
 	}
-	var id, ret string
-	if exp.Source != "" {
-		arr := strings.SplitN(exp.Source, ":", 2)
-		if len(arr) > 1 {
-			id = arr[1]
+	p.bodyParsed = true
+	switch p.Body.Type() {
+	case pcommon.ValueTypeMap:
+		p.bodyMap = p.Body.Map()
+	case pcommon.ValueTypeStr:
+		raw := make(map[string]any)
+		p.bodyErr = json.Unmarshal([]byte(p.Body.Str()), &raw)
+		if p.bodyErr == nil {
+			p.bodyMap = pcommon.NewMap()
+			p.bodyErr = p.bodyMap.FromRaw(raw)
 		}
-		switch arr[0] {
-		case CfgSourceLit:
-			ret = id
-		case CfgSourceRattr:
-			ret = evalMap(id, p.Rattr)
-		case CfgSourceAttr:
-			ret = evalMap(id, p.Attr)
-		case CfgSourceBody:
-			switch p.Body.Type() {
-			case pcommon.ValueTypeMap:
-				if id != "" {
-					ret = evalMap(id, p.Body.Map())
-				} else {
-					ret = p.Body.AsString()
-				}
-			case pcommon.ValueTypeStr:
-				raw := make(map[string]any)
-				if id != "" {
-					err := json.Unmarshal([]byte(p.Body.AsString()), &raw)
-					if err != nil {
-						// Can't index into non object
-						return "", ""
-					}
-					av := pcommon.NewValueEmpty()
-					if av.SetEmptyMap().FromRaw(raw) == nil {
-						ret = evalMap(id, av.Map())
-						break
-					}
-				}
-				fallthrough
-			default:
-				ret = evalValue(id, p.Body)
-			}
+	default:
+		p.bodyErr = fmt.Errorf("body of type %s is not an object", p.Body.Type())
+	}
+	return p.bodyMap, p.bodyErr
+}
+
+func (p *Parser) evalBody(path keyPath) string {
+	if len(path) == 0 {
+		if p.Body.Type() == pcommon.ValueTypeMap {
+			return p.Body.AsString()
 		}
-		ret = FilterASCII(ret)
-	} else { // Op must be populated
-		var ret2 string
-		id, ret = p.evalExp(exp.Exps[0])
-		numExps, _ := cfgOpMap[exp.Op]
-		if numExps == CMaxNumExps {
-			for _, exp2 := range exp.Exps[1:] {
-				_, ret2 = p.evalExp(exp2)
-				switch exp.Op {
-				case CfgOpAnd:
-					ret += ` ` + ret2
-				case CfgOpOr:
-					if ret == "" {
-						ret = ret2
-					}
-				}
-			}
+		return evalValue("", p.Body)
+	}
+	switch p.Body.Type() {
+	case pcommon.ValueTypeSlice:
+		return evalValues(path.lookup(p.Body, nil))
+	case pcommon.ValueTypeMap, pcommon.ValueTypeStr:
+		body, err := p.ParseBody()
+		if err != nil {
+			// Can't index into non object
+			return ""
 		}
-		if numExps > 1 {
-			_, ret2 = p.evalExp(exp.Exps[1])
+		return evalMap(path, body)
+	}
+	return evalValue("", p.Body)
+}
+
+// evalMeta returns a field of the log record outside its attributes and body.
+func (p *Parser) evalMeta(key string) string {
+	lr := p.Record
+	switch key {
+	case CfgMetaSeverityText:
+		return lr.SeverityText()
+	case CfgMetaSeverityNumber:
+		return strconv.Itoa(int(lr.SeverityNumber()))
+	case CfgMetaTraceID:
+		if lr.TraceID().IsEmpty() {
+			return ""
 		}
-		switch exp.Op {
-		case CfgOpRmprefix:
-			if strings.HasPrefix(ret, ret2) {
-				ret = ret[len(ret2):]
-			}
-		case CfgOpRmsuffix:
-			if strings.HasSuffix(ret, ret2) {
-				ret = ret[:len(ret)-len(ret2)]
-			}
-		case CfgOpRmtail:
-			idx := strings.LastIndex(ret, ret2)
-			if idx > -1 {
-				ret = ret[:idx]
-			}
-		case CfgOpAlphaNum:
-			var new string
-			for _, c := range ret {
-				if unicode.IsUpper(c) || unicode.IsLower(c) || unicode.IsDigit(c) {
-					new += string(c)
-				}
-			}
-			ret = new
-		case CfgOpLc:
-			ret = strings.ToLower(ret)
-		case CfgOpUnescape:
-			// Remove the ESC character. This is special-cased because
-			// embedding escapes into configurations/configs can be
-			// problematic.
-			ret = strings.Replace(ret, "\x1B", "", -1)
-		case CfgOpReplace:
-			_, ret3 := p.evalExp(exp.Exps[2])
-			ret = strings.Replace(ret, ret2, ret3, -1)
-		case CfgOpRegexp:
-			r, err := regexp.Compile(ret2)
-			if err == nil {
-				arr := r.FindStringSubmatch(ret)
-				ret = ""
-				if len(arr) > 1 {
-					ret = strings.Join(arr[1:], "")
-				}
-			} else {
-				p.Log.Info("failed to compile regexp",
-					zap.String("id", id),
-					zap.String("value", ret2))
-			}
+		return lr.TraceID().String()
+	case CfgMetaSpanID:
+		if lr.SpanID().IsEmpty() {
+			return ""
 		}
+		return lr.SpanID().String()
+	case CfgMetaTimestamp:
+		return formatTimestamp(lr.Timestamp())
+	case CfgMetaObservedTimestamp:
+		return formatTimestamp(lr.ObservedTimestamp())
+	case CfgMetaFlags:
+		return strconv.FormatUint(uint64(lr.Flags()), 10)
 	}
-	return id, ret
+	return ""
 }
 
-func (p *Parser) EvalElem(attribute *ConfigAttribute) (string, string) {
-	if attribute == nil {
-		return "", ""
-	}
-	id, ret := p.evalExp(attribute.Exp)
-	if attribute.Rename != "" {
-		id = attribute.Rename
+func formatTimestamp(ts pcommon.Timestamp) string {
+	if ts == 0 {
+		return ""
 	}
-	if attribute.Validate != "" {
-		r := regexp.MustCompile(attribute.Validate)
-		if !r.MatchString(ret) {
-			p.Log.Info("failed to validate regexp",
-				zap.String("id", id),
-				zap.String("regexp", attribute.Validate),
-				zap.String("value", ret))
-			ret = ""
-		}
+	return ts.AsTime().Format(time.RFC3339Nano)
+}
+
+// evalBodyJSON reports whether the body is an object or a JSON object string.
+func (p *Parser) evalBodyJSON() string {
+	if _, err := p.ParseBody(); err != nil {
+		return ""
 	}
-	return id, ret
+	return "true"
 }
 
 type ConfigResult struct {
@@ -387,114 +319,214 @@ type ConfigResult struct {
 	Labels       []string `mapstructure:"labels"`
 	Message      string   `mapstructure:"message"`
 	Format       string   `mapstructure:"format"`
+	// containerTime is the timestamp of a decoded container log line,
+	// partial is set for a fragment of a longer line.
+	containerTime string
+	partial       bool
+	// fragmentStream is the stream of a Docker entry.
+	fragmentStream string
+	// fields holds the attributes of the json format.
+	fields map[string]string
 }
 
+// MatchProfile compiles the configured profiles and matches the log
+// record against them, so it compiles them for every call. The processor
+// compiles the profiles once when it is created with newProfileMatcher
+// instead.
 func (c *Config) MatchProfile(log *zap.Logger, rl plog.ResourceLogs, ils plog.ScopeLogs, lr plog.LogRecord) (*ConfigResult, *StreamTokenReq, error) {
+	m, err := newProfileMatcher(c)
+	if err != nil {
+		return nil, nil, err
+	}
+	return m.MatchProfile(log, rl, ils, lr)
+}
+
+func (m *profileMatcher) MatchProfile(log *zap.Logger, rl plog.ResourceLogs, ils plog.ScopeLogs, lr plog.LogRecord) (*ConfigResult, *StreamTokenReq, error) {
+	return m.matchRecord(log, m.newResourceCache(rl), ils, lr)
+}
+
+// matchRecord matches a log record of the resource held by res, values
+// that only depend on the resource are reused from earlier records.
+func (m *profileMatcher) matchRecord(log *zap.Logger, res *resourceCache, ils plog.ScopeLogs, lr plog.LogRecord) (*ConfigResult, *StreamTokenReq, error) {
+	profile, gen, req, err := m.match(log, res, ils, lr)
+	if err != nil {
+		return nil, nil, err
+	}
+	profile.redactContainerMessage(gen, m.telemetry)
+	profile.formatMessage(gen, lr)
+	return gen, req, nil
+}
+
+// match returns the first profile matching the log record with the
+// message before it is formatted.
+func (m *profileMatcher) match(log *zap.Logger, res *resourceCache, ils plog.ScopeLogs, lr plog.LogRecord) (*compiledProfile, *ConfigResult, *StreamTokenReq, error) {
 	var id, ret string
 	reasons := []string{}
-	for idx, profile := range c.Profiles {
+	parser := Parser{
+		Log:    log,
+		Rattr:  res.rattr,
+		Attr:   lr.Attributes(),
+		Body:   lr.Body(),
+		Scope:  ils.Scope(),
+		Record: lr,
+		res:    res,
+	}
+PROFILES:
+	for idx, profile := range m.profiles {
+		if len(profile.extract) > 0 {
+			parser.vars = make(map[string]string)
+			for _, extract := range profile.extract {
+				extract.eval(&parser)
+			}
+		} else {
+			parser.vars = nil
+		}
+		for condIdx, cond := range profile.match {
+			if reason := cond.eval(&parser); reason != "" {
+				reasons = append(reasons, fmt.Sprintf("profile %d failed match %d %s", idx, condIdx, reason))
+				continue PROFILES
+			}
+		}
 		req := newStreamTokenReq()
 		gen := ConfigResult{}
-		parser := Parser{
-			Log:   log,
-			Rattr: rl.Resource().Attributes(),
-			Attr:  lr.Attributes(),
-			Body:  lr.Body(),
-		}
-		id, gen.ServiceGroup = parser.EvalElem(profile.ServiceGroup)
+		id, gen.ServiceGroup = profile.serviceGroup.eval(&parser)
 		if gen.ServiceGroup == "" {
-			reasons = append(reasons, "service_group")
+			reasons = append(reasons, fmt.Sprintf("profile %d failed to find service_group", idx))
 			continue
 		}
 		req.Ids[id] = gen.ServiceGroup
-		id, gen.Host = parser.EvalElem(profile.Host)
+		id, gen.Host = profile.host.eval(&parser)
 		if gen.Host == "" {
-			reasons = append(reasons, "host")
+			reasons = append(reasons, fmt.Sprintf("profile %d failed to find host", idx))
 			continue
 		}
 		req.Ids[id] = gen.Host
-		id, gen.Logbasename = parser.EvalElem(profile.Logbasename)
+		id, gen.Logbasename = profile.logbasename.eval(&parser)
 		if gen.Logbasename == "" {
-			reasons = append(reasons, "logbasename")
+			reasons = append(reasons, fmt.Sprintf("profile %d failed to find logbasename", idx))
 			continue
 		}
-		if lr.SeverityNumber() == plog.SeverityNumberUnspecified {
-			sevNum, ok := sevText2Num[lr.SeverityText()]
-			if ok {
-				lr.SetSeverityNumber(sevNum)
-			}
+		if lr.SeverityNumber() == plog.SeverityNumberUnspecified && lr.SeverityText() != "" {
+			lr.SetSeverityNumber(profile.severityRules.severityFromText(lr.SeverityText()))
 		}
-		if profile.Severity != nil {
-			_, sevText := parser.EvalElem(profile.Severity)
+		if profile.severity != nil {
+			_, sevText := profile.severity.eval(&parser)
 			if sevText == "" {
-				reasons = append(reasons, "severity")
+				reasons = append(reasons, fmt.Sprintf("profile %d failed to find severity", idx))
 				continue
 			}
-			sevText = strings.ToUpper(sevText)
-			sevNum := plog.SeverityNumberUnspecified
-			sevNum, _ = sevTextMap[sevText]
-			if sevNum == plog.SeverityNumberUnspecified &&
-				len(sevText) == 3 {
-				// Interpret as HTTP status
-				switch sevText[0] {
-				case '1', '2':
-					sevNum = plog.SeverityNumberInfo
-				case '3':
-					sevNum = plog.SeverityNumberDebug
-				case '4', '5':
-					sevNum = plog.SeverityNumberError
-				}
-			}
-			lr.SetSeverityNumber(sevNum)
+			lr.SetSeverityNumber(profile.severityRules.severityFromText(sevText))
 		}
 		req.Ids[id] = gen.Logbasename
 		req.Logbasename = gen.Logbasename
-		for _, label := range profile.Labels {
-			id, ret = parser.EvalElem(label)
+		for _, extract := range profile.extract {
+			if !extract.labels {
+				continue
+			}
+			for _, name := range extract.names {
+				if val, ok := parser.vars[name]; ok {
+					req.Cfgs[name] = val
+				}
+			}
+		}
+		for _, label := range profile.labels {
+			id, ret = label.eval(&parser)
 			req.Cfgs[id] = ret
 		}
-		_, gen.Message = parser.EvalElem(profile.Message)
+		_, gen.Message = profile.message.eval(&parser)
 		if gen.Message == "" {
-			if idx >= len(c.Profiles)-1 {
+			if idx >= len(m.profiles)-1 {
 				// If this is the last configured profile and we have no message body,
 				// report it as a warning instead of an error
-				return nil, nil, errEmptyLine
+				return nil, nil, nil, errEmptyLine
 			}
-			reasons = append(reasons, "message")
+			reasons = append(reasons, fmt.Sprintf("profile %d failed to find message", idx))
 			continue
 		}
-		// FORMAT MESSAGE
-		switch profile.Format {
-		case CfgFormatEvent:
-			var timestamp time.Time
-			const RFC3339Micro = "2006-01-02T15:04:05.999999Z07:00"
-			if lr.Timestamp() != 0 {
-				timestamp = time.Unix(0, int64(lr.Timestamp()))
-			} else {
-				timestamp = time.Unix(0, int64(lr.ObservedTimestamp()))
+		if profile.json != nil {
+			gen.fields = profile.json.evalAttrs(&parser)
+		}
+		if profile.format == CfgFormatContainer {
+			if entry, ok := parseContainerEntry(gen.Message, profile.filter); ok {
+				gen.Message, gen.containerTime, gen.partial = entry.log, entry.timestamp, entry.partial
+				gen.fragmentStream = entry.fragmentStream
+				if entry.stream != "" {
+					req.Stream = entry.stream
+				}
 			}
-			sevText, _ := severityMap[lr.SeverityNumber()]
-			if len(gen.Message) > 2 && gen.Message[0] == '{' {
-				// I use 2 above because we are inserting severity with a comma after,
-				// so we expect both open & close with something inbeteen
-				gen.Message = "ze_tm=" + strconv.FormatInt(timestamp.UnixMilli(), 10) + `,msg={"severity":"` + sevText + `",` + gen.Message[1:]
+		}
+		if profile.severity == nil && profile.severityDetect &&
+			lr.SeverityNumber() == plog.SeverityNumberUnspecified {
+			lr.SetSeverityNumber(profile.severityRules.detectSeverity(gen.Message))
+		}
+		// only records matched by the profile are dropped
+		if rule, ok := profile.dropRule(&parser); ok {
+			m.telemetry.recordDropped(idx, rule)
+			return nil, nil, nil, errDropped
+		}
+		if sev := lr.SeverityNumber(); sev != plog.SeverityNumberUnspecified && sev < profile.minSeverity {
+			m.telemetry.recordDropped(idx, dropRuleMinSeverity)
+			return nil, nil, nil, errDropped
+		}
+		loc := time.Local
+		if _, tz := profile.tz.eval(&parser); tz != "" {
+			if zone, first, err := m.zones.location(tz); err != nil {
+				if first {
+					log.Info("failed to load timezone", zap.Int("profile", idx), zap.String("tz", tz), zap.Error(err))
+				} else {
+					log.Debug("failed to load timezone", zap.Int("profile", idx), zap.String("tz", tz), zap.Error(err))
+				}
 			} else {
-				gen.Message = "ze_tm=" + strconv.FormatInt(timestamp.UnixMilli(), 10) + ",msg=" + timestamp.UTC().Format(RFC3339Micro) + " " + sevText + " " + gen.Message
+				loc = zone
+				req.Tz = zone.String()
 			}
-		case CfgFormatContainer:
-			req.ContainerLog = true
-			if len(gen.Message) > 2 && gen.Message[0] == '{' {
-				var contLog ContainerLogEntry
-				err := json.Unmarshal([]byte(gen.Message), &contLog)
-				if err == nil {
-					gen.Message = contLog.Timestamp + " " + contLog.Log
-				}
+		}
+		if profile.timestamp != nil {
+			ts, ok, err := profile.timestamp.parse(&parser, loc)
+			switch {
+			case err != nil:
+				m.telemetry.recordTimestampParseFailure(idx)
+				log.Debug("failed to parse timestamp", zap.Int("profile", idx), zap.Error(err))
+			case ok:
+				lr.SetTimestamp(pcommon.NewTimestampFromTime(ts))
 			}
 		}
-		// fmt.Printf("MSG bytes: %+v\n", []byte(gen.Message))
-		// fmt.Printf("MSG string: %s\n", gen.Message)
-		gen.Format = profile.Format
-		return &gen, &req, nil
+		// the message of container logs is redacted after reassembly
+		for ruleIdx, count := range profile.redact.redactResult(&gen, &req, profile.format != CfgFormatContainer) {
+			m.telemetry.recordRedactions(profile.redact[ruleIdx].name, count)
+		}
+		req.ContainerLog = profile.format == CfgFormatContainer
+		gen.Format = profile.format
+		return profile, &gen, &req, nil
+	}
+	return nil, nil, nil, fmt.Errorf("No matching profile for log record, %s", strings.Join(reasons, "; "))
+}
+
+// formatMessage formats the message of a log record matched by the
+// profile.
+func (profile *compiledProfile) formatMessage(gen *ConfigResult, lr plog.LogRecord) {
+	switch profile.format {
+	case CfgFormatEvent:
+		var timestamp time.Time
+		const RFC3339Micro = "2006-01-02T15:04:05.999999Z07:00"
+		if lr.Timestamp() != 0 {
+			timestamp = time.Unix(0, int64(lr.Timestamp()))
+		} else {
+			timestamp = time.Unix(0, int64(lr.ObservedTimestamp()))
+		}
+		sevText, _ := severityMap[lr.SeverityNumber()]
+		if len(gen.Message) > 2 && gen.Message[0] == '{' {
+			// I use 2 above because we are inserting severity with a comma after,
+			// so we expect both open & close with something inbeteen
+			gen.Message = "ze_tm=" + strconv.FormatInt(timestamp.UnixMilli(), 10) + `,msg={"severity":"` + sevText + `",` + gen.Message[1:]
+		} else {
+			gen.Message = "ze_tm=" + strconv.FormatInt(timestamp.UnixMilli(), 10) + ",msg=" + timestamp.UTC().Format(RFC3339Micro) + " " + sevText + " " + gen.Message
+		}
+	case CfgFormatContainer:
+		if gen.containerTime != "" {
+			gen.Message = gen.containerTime + " " + gen.Message
+		}
+	case CfgFormatJSON:
+		gen.Message = profile.json.format(gen, lr)
 	}
-	return nil, nil, fmt.Errorf("No matching profile for log record, failed to find %v", reasons)
 }
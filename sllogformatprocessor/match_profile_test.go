@@ -2,8 +2,11 @@ package sllogformatprocessor
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/plog"
 	"go.uber.org/zap"
 )
@@ -197,3 +200,531 @@ func createLogRecord(scopeLogs plog.ScopeLogs, logLine string) {
 	logRecord := scopeLogs.LogRecords().AppendEmpty()
 	logRecord.Body().SetStr(logLine)
 }
+
+func benchmarkConfig() *Config {
+	return &Config{
+		Profiles: []ConfigProfile{
+			{
+				ServiceGroup: &ConfigAttribute{
+					Exp:    &ConfigExpression{Source: "lit:default"},
+					Rename: "ze_deployment_name",
+				},
+				Host: &ConfigAttribute{
+					Exp:      &ConfigExpression{Source: "body:computer"},
+					Rename:   "host",
+					Validate: "^[a-z0-9.-]+$",
+				},
+				Logbasename: &ConfigAttribute{
+					Exp: &ConfigExpression{
+						Op: CfgOpLc,
+						Exps: []*ConfigExpression{{
+							Op: CfgOpAlphaNum,
+							Exps: []*ConfigExpression{{
+								Op: CfgOpRmprefix,
+								Exps: []*ConfigExpression{
+									{Source: "body:provider.name"},
+									{Source: "lit:Microsoft-Windows-"},
+								},
+							}},
+						}},
+					},
+					Rename: "logbasename",
+				},
+				Labels: []*ConfigAttribute{
+					{
+						Exp: &ConfigExpression{
+							Op: CfgOpRegexp,
+							Exps: []*ConfigExpression{
+								{Source: "body:channel"},
+								{Source: "lit:^(\\w+)"},
+							},
+						},
+						Rename: "win_channel",
+					},
+				},
+				Message: &ConfigAttribute{
+					Exp: &ConfigExpression{
+						Op: CfgOpOr,
+						Exps: []*ConfigExpression{
+							{Source: "body:message"},
+							{Source: "body:event_data"},
+						},
+					},
+				},
+				Format: CfgFormatEvent,
+			},
+		},
+	}
+}
+
+func benchmarkLogs() plog.Logs {
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr("host.name", "myhost")
+	lr := rl.ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	body := lr.Body().SetEmptyMap()
+	body.PutStr("computer", "myhost.example.com")
+	body.PutStr("channel", "Security Log")
+	body.PutStr("message", "An account was successfully logged on.")
+	body.PutEmptyMap("provider").PutStr("name", "Microsoft-Windows-Security-Auditing")
+	return ld
+}
+
+func TestCompiledMatchProfile(t *testing.T) {
+	cfg := benchmarkConfig()
+	require.NoError(t, cfg.Validate())
+	m, err := newProfileMatcher(cfg)
+	require.NoError(t, err)
+	ld := benchmarkLogs()
+	rl := ld.ResourceLogs().At(0)
+	ils := rl.ScopeLogs().At(0)
+	gen, req, err := m.MatchProfile(zap.NewNop(), rl, ils, ils.LogRecords().At(0))
+	require.NoError(t, err)
+	assert.Equal(t, "default", gen.ServiceGroup)
+	assert.Equal(t, "myhost.example.com", gen.Host)
+	assert.Equal(t, "securityauditing", gen.Logbasename)
+	assert.Equal(t, "Security", req.Cfgs["win_channel"])
+	assert.Contains(t, gen.Message, "An account was successfully logged on.")
+}
+
+func TestCompileProfileInvalidRegexp(t *testing.T) {
+	cfg := benchmarkConfig()
+	cfg.Profiles[0].Labels[0].Exp.Exps[1].Source = "lit:(["
+	_, err := newProfileMatcher(cfg)
+	assert.Error(t, err)
+}
+
+// BenchmarkMatchProfileCompiled measures the compiled profiles used by the processor.
+func BenchmarkMatchProfileCompiled(b *testing.B) {
+	m, err := newProfileMatcher(benchmarkConfig())
+	if err != nil {
+		b.Fatal(err)
+	}
+	logger := zap.NewNop()
+	ld := benchmarkLogs()
+	rl := ld.ResourceLogs().At(0)
+	ils := rl.ScopeLogs().At(0)
+	lr := ils.LogRecords().At(0)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := m.MatchProfile(logger, rl, ils, lr); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestResourceCache(t *testing.T) {
+	cfg := &Config{
+		Profiles: []ConfigProfile{
+			{
+				ServiceGroup: &ConfigAttribute{
+					Exp:    &ConfigExpression{Source: "rattr:k8s.namespace.name"},
+					Rename: "ze_deployment_name",
+				},
+				Host: &ConfigAttribute{
+					Exp:    &ConfigExpression{Source: "rattr:host.name"},
+					Rename: "host",
+				},
+				Logbasename: &ConfigAttribute{
+					Exp: &ConfigExpression{
+						Op: CfgOpOr,
+						Exps: []*ConfigExpression{
+							{Source: "attr:app"},
+							{Source: "rattr:k8s.container.name"},
+						},
+					},
+					Rename: "logbasename",
+				},
+				Message: &ConfigAttribute{
+					Exp: &ConfigExpression{Source: "body"},
+				},
+			},
+		},
+	}
+	m, err := newProfileMatcher(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, 3, m.numSlots)
+	assert.Equal(t, 0, m.profiles[0].serviceGroup.slot)
+	assert.Equal(t, 1, m.profiles[0].host.slot)
+	assert.Equal(t, -1, m.profiles[0].logbasename.slot)
+
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr("k8s.namespace.name", "prod")
+	rl.Resource().Attributes().PutStr("host.name", "node1")
+	rl.Resource().Attributes().PutStr("k8s.container.name", "nginx")
+	ils := rl.ScopeLogs().AppendEmpty()
+	createLogRecord(ils, "first")
+	createLogRecord(ils, "second")
+	ils.LogRecords().At(1).Attributes().PutStr("app", "api")
+
+	res := m.newResourceCache(rl)
+	gen, req, err := m.matchRecord(zap.NewNop(), res, ils, ils.LogRecords().At(0))
+	require.NoError(t, err)
+	assert.Equal(t, "prod", gen.ServiceGroup)
+	assert.Equal(t, "node1", req.Ids["host"])
+	assert.Equal(t, "nginx", gen.Logbasename)
+
+	// Resource level values are not evaluated again for the same resource
+	rl.Resource().Attributes().PutStr("host.name", "node2")
+	gen, _, err = m.matchRecord(zap.NewNop(), res, ils, ils.LogRecords().At(1))
+	require.NoError(t, err)
+	assert.Equal(t, "node1", gen.Host)
+	assert.Equal(t, "api", gen.Logbasename)
+
+	gen, _, err = m.MatchProfile(zap.NewNop(), rl, ils, ils.LogRecords().At(1))
+	require.NoError(t, err)
+	assert.Equal(t, "node2", gen.Host)
+}
+
+func TestParserParseBody(t *testing.T) {
+	testCases := []struct {
+		name     string
+		body     func(lr plog.LogRecord)
+		key      string
+		expected string
+		json     string
+		parseErr bool
+	}{
+		{
+			name:     "JSON string body",
+			body:     func(lr plog.LogRecord) { lr.Body().SetStr(`{"level":"info","msg":"started"}`) },
+			key:      "msg",
+			expected: "started",
+			json:     "true",
+		},
+		{
+			name:     "JSON string body missing key",
+			body:     func(lr plog.LogRecord) { lr.Body().SetStr(`{"level":"info","msg":"started"}`) },
+			key:      "host",
+			expected: "",
+			json:     "true",
+		},
+		{
+			name:     "Plain string body",
+			body:     func(lr plog.LogRecord) { lr.Body().SetStr(`level=info msg=started`) },
+			key:      "msg",
+			expected: "",
+			json:     "",
+			parseErr: true,
+		},
+		{
+			name:     "Map body",
+			body:     func(lr plog.LogRecord) { lr.Body().SetEmptyMap().PutStr("msg", "started") },
+			key:      "msg",
+			expected: "started",
+			json:     "true",
+		},
+		{
+			name:     "Int body",
+			body:     func(lr plog.LogRecord) { lr.Body().SetInt(42) },
+			key:      "msg",
+			expected: "42",
+			json:     "",
+			parseErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			lr := plog.NewLogRecord()
+			tc.body(lr)
+			p := Parser{Log: zap.NewNop(), Attr: lr.Attributes(), Body: lr.Body()}
+			body, err := compileSource("body:" + tc.key)
+			require.NoError(t, err)
+			bodyJSON, err := compileSource(CfgSourceBodyJSON)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, body.eval(&p))
+			assert.Equal(t, tc.json, bodyJSON.eval(&p))
+			assert.True(t, p.bodyParsed)
+			_, err = p.ParseBody()
+			assert.Equal(t, tc.parseErr, err != nil)
+		})
+	}
+}
+
+func TestParserParseBodyOnce(t *testing.T) {
+	lr := plog.NewLogRecord()
+	lr.Body().SetStr(`{"msg":"started"}`)
+	p := Parser{Log: zap.NewNop(), Body: lr.Body()}
+	body, err := p.ParseBody()
+	require.NoError(t, err)
+	body.PutStr("cached", "yes")
+	body2, err := p.ParseBody()
+	require.NoError(t, err)
+	cached, ok := body2.Get("cached")
+	assert.True(t, ok)
+	assert.Equal(t, "yes", cached.Str())
+}
+
+func TestScopeAndMetaSources(t *testing.T) {
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	ils := rl.ScopeLogs().AppendEmpty()
+	ils.Scope().SetName("otelcol/windowseventlogreceiver")
+	ils.Scope().SetVersion("0.109.0")
+	ils.Scope().Attributes().PutStr("log.source", "eventlog")
+	lr := ils.LogRecords().AppendEmpty()
+	lr.SetSeverityText("Warning")
+	lr.SetSeverityNumber(plog.SeverityNumberWarn)
+	lr.SetTraceID([16]byte{0x5b, 0x8e, 0xff, 0xf7, 0x98, 0x03, 0x81, 0x03, 0xd2, 0x69, 0xb6, 0x33, 0x81, 0x3f, 0xc6, 0x0c})
+	lr.SetSpanID([8]byte{0xee, 0xe1, 0x9b, 0x7e, 0xc3, 0xc1, 0xb1, 0x74})
+	lr.SetTimestamp(pcommon.NewTimestampFromTime(time.Date(2023, 10, 6, 0, 17, 9, 669794202, time.UTC)))
+	lr.SetFlags(plog.DefaultLogRecordFlags.WithIsSampled(true))
+	p := Parser{Log: zap.NewNop(), Scope: ils.Scope(), Record: lr}
+
+	testCases := []struct {
+		source   string
+		expected string
+	}{
+		{"scope:name", "otelcol/windowseventlogreceiver"},
+		{"scope:version", "0.109.0"},
+		{"scope:attr.log.source", "eventlog"},
+		{"scope:attr.missing", ""},
+		{"meta:severity_text", "Warning"},
+		{"meta:severity_number", "13"},
+		{"meta:trace_id", "5b8efff798038103d269b633813fc60c"},
+		{"meta:span_id", "eee19b7ec3c1b174"},
+		{"meta:timestamp", "2023-10-06T00:17:09.669794202Z"},
+		{"meta:observed_timestamp", ""},
+		{"meta:flags", "1"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.source, func(t *testing.T) {
+			exp, err := compileSource(tc.source)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, exp.eval(&p))
+		})
+	}
+}
+
+func lit(value string) *ConfigExpression {
+	return &ConfigExpression{Source: "lit:" + value}
+}
+
+func op(name string, exps ...*ConfigExpression) *ConfigExpression {
+	return &ConfigExpression{Op: name, Exps: exps}
+}
+
+func testCompiler(t *testing.T) *profileCompiler {
+	c, err := newProfileCompiler(&Config{})
+	require.NoError(t, err)
+	return c
+}
+
+func TestStringOperators(t *testing.T) {
+	lr := plog.NewLogRecord()
+	lr.Attributes().PutStr("host", "  web-01.Example.COM  ")
+	lr.Attributes().PutStr("path", "/var/log/pods/default_nginx-1_0f3a/nginx/0.log")
+	p := Parser{Log: zap.NewNop(), Attr: lr.Attributes(), Body: lr.Body(), Record: lr}
+	host := &ConfigExpression{Source: "attr:host"}
+	path := &ConfigExpression{Source: "attr:path"}
+
+	testCases := []struct {
+		name     string
+		exp      *ConfigExpression
+		expected string
+	}{
+		{"uc", op(CfgOpUc, lit("nginx")), "NGINX"},
+		{"trim", op(CfgOpTrim, host), "web-01.Example.COM"},
+		{"trimset", op(CfgOpTrimset, lit("--nginx--"), lit("-")), "nginx"},
+		{"substr", op(CfgOpSubstr, lit("postgresql"), lit("0"), lit("8")), "postgres"},
+		{"substr to end", op(CfgOpSubstr, lit("postgresql"), lit("4"), lit("-1")), "gresql"},
+		{"substr negative start", op(CfgOpSubstr, lit("postgresql"), lit("-3"), lit("2")), "sq"},
+		{"substr past end", op(CfgOpSubstr, lit("abc"), lit("5"), lit("2")), ""},
+		{"substr long length", op(CfgOpSubstr, lit("abc"), lit("1"), lit("10")), "bc"},
+		{"split", op(CfgOpSplit, path, lit("/"), lit("5")), "nginx"},
+		{"split last", op(CfgOpSplit, path, lit("/"), lit("-1")), "0.log"},
+		{"split out of range", op(CfgOpSplit, path, lit("/"), lit("10")), ""},
+		{"split host", op(CfgOpSplit, op(CfgOpTrim, host), lit("."), lit("0")), "web-01"},
+		{"truncate", op(CfgOpTruncate, lit("postgresql"), lit("8")), "postgres"},
+		{"truncate short", op(CfgOpTruncate, lit("pg"), lit("8")), "pg"},
+		{"format", op(CfgOpFormat, lit("%s-%s"), op(CfgOpLc, op(CfgOpTrim, host)), op(CfgOpSplit, path, lit("/"), lit("5"))),
+			"web-01.example.com-nginx"},
+		{"format percent", op(CfgOpFormat, lit("%s 100%%"), lit("done")), "done 100%"},
+		{"format indexes", op(CfgOpFormat, lit("%[2]s %[1]s %[2]s"), lit("a"), lit("b")), "b a b"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			exp, err := testCompiler(t).compileExp(tc.exp)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, exp.eval(&p))
+		})
+	}
+}
+
+func TestConditionalOperators(t *testing.T) {
+	lr := plog.NewLogRecord()
+	lr.Attributes().PutStr("log.file.path", `C:\inetpub\logs\LogFiles\W3SVC1\u_ex231006.log`)
+	lr.Attributes().PutStr("level", "warn")
+	p := Parser{Log: zap.NewNop(), Attr: lr.Attributes(), Body: lr.Body(), Record: lr}
+	path := &ConfigExpression{Source: "attr:log.file.path"}
+	level := &ConfigExpression{Source: "attr:level"}
+	missing := &ConfigExpression{Source: "attr:missing"}
+
+	testCases := []struct {
+		name     string
+		exp      *ConfigExpression
+		expected string
+	}{
+		{"eq", op(CfgOpEq, level, lit("warn")), "true"},
+		{"eq false", op(CfgOpEq, level, lit("error")), ""},
+		{"ne", op(CfgOpNe, level, lit("error")), "true"},
+		{"ne false", op(CfgOpNe, level, lit("warn")), ""},
+		{"contains", op(CfgOpContains, path, lit("W3SVC")), "true"},
+		{"contains false", op(CfgOpContains, path, lit("nginx")), ""},
+		{"matches", op(CfgOpMatches, path, lit(`\\W3SVC\d+\\`)), "true"},
+		{"matches false", op(CfgOpMatches, level, lit(`^err`)), ""},
+		{"in", op(CfgOpIn, level, lit("warn"), lit("error")), "true"},
+		{"in false", op(CfgOpIn, level, lit("info"), lit("debug")), ""},
+		{"if then", op(CfgOpIf, op(CfgOpContains, path, lit("W3SVC")), lit("iis"), lit("other")), "iis"},
+		{"if else", op(CfgOpIf, missing, lit("iis"), lit("other")), "other"},
+		{"if logbasename",
+			op(CfgOpIf,
+				op(CfgOpContains, path, lit("nginx")),
+				lit("nginx"),
+				op(CfgOpRmsuffix, op(CfgOpSplit, path, lit(`\`), lit("-1")), lit(".log"))),
+			"u_ex231006"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			exp, err := testCompiler(t).compileExp(tc.exp)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, exp.eval(&p))
+		})
+	}
+}
+
+func TestMatchConditions(t *testing.T) {
+	exists := true
+	notExists := false
+	cfg := &Config{
+		Profiles: []ConfigProfile{
+			{
+				Match: []*ConfigCondition{
+					{
+						Exp:    &ConfigExpression{Source: "scope:name"},
+						Equals: "otelcol/k8sobjectsreceiver",
+					},
+				},
+				ServiceGroup: &ConfigAttribute{Exp: lit("default"), Rename: "ze_deployment_name"},
+				Host:         &ConfigAttribute{Exp: lit("cluster"), Rename: "host"},
+				Logbasename:  &ConfigAttribute{Exp: lit("k8sevents"), Rename: "logbasename"},
+				Message:      &ConfigAttribute{Exp: &ConfigExpression{Source: "body:message"}},
+			},
+			{
+				Match: []*ConfigCondition{
+					{
+						Exp:    &ConfigExpression{Source: "rattr:source"},
+						Regexp: "^(docker|containerd)$",
+					},
+					{
+						Exp:    &ConfigExpression{Source: "attr:container_id"},
+						Exists: &exists,
+					},
+					{
+						Exp:    &ConfigExpression{Source: "attr:ignore"},
+						Exists: &notExists,
+					},
+				},
+				ServiceGroup: &ConfigAttribute{Exp: lit("default"), Rename: "ze_deployment_name"},
+				Host:         &ConfigAttribute{Exp: &ConfigExpression{Source: "rattr:host.name"}, Rename: "host"},
+				Logbasename:  &ConfigAttribute{Exp: &ConfigExpression{Source: "attr:container_id"}, Rename: "logbasename"},
+				Message:      &ConfigAttribute{Exp: &ConfigExpression{Source: "body"}},
+			},
+		},
+	}
+	require.NoError(t, cfg.Validate())
+	m, err := newProfileMatcher(cfg)
+	require.NoError(t, err)
+
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr("host.name", "node1")
+	rl.Resource().Attributes().PutStr("source", "docker")
+	ils := rl.ScopeLogs().AppendEmpty()
+	lr := ils.LogRecords().AppendEmpty()
+	lr.Body().SetStr(`{"message":"pod started"}`)
+	lr.Attributes().PutStr("container_id", "0f3a")
+
+	// The k8s events profile would match all fields, but the scope does not match
+	gen, _, err := m.MatchProfile(zap.NewNop(), rl, ils, lr)
+	require.NoError(t, err)
+	assert.Equal(t, "0f3a", gen.Logbasename)
+
+	ils.Scope().SetName("otelcol/k8sobjectsreceiver")
+	gen, _, err = m.MatchProfile(zap.NewNop(), rl, ils, lr)
+	require.NoError(t, err)
+	assert.Equal(t, "k8sevents", gen.Logbasename)
+
+	ils.Scope().SetName("")
+	lr.Attributes().PutStr("ignore", "yes")
+	_, _, err = m.MatchProfile(zap.NewNop(), rl, ils, lr)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `profile 0 failed match 0 scope:name equals "otelcol/k8sobjectsreceiver", got ""`)
+	assert.Contains(t, err.Error(), `profile 1 failed match 2 attr:ignore exists false`)
+
+	lr.Attributes().Remove("ignore")
+	rl.Resource().Attributes().PutStr("source", "journald")
+	_, _, err = m.MatchProfile(zap.NewNop(), rl, ils, lr)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `profile 1 failed match 0 rattr:source regexp "^(docker|containerd)$", got "journald"`)
+}
+
+func TestExtract(t *testing.T) {
+	cfg := &Config{
+		Profiles: []ConfigProfile{
+			{
+				Extract: []*ConfigExtract{
+					{
+						Exp:    &ConfigExpression{Source: `attr:["log.file.path"]`},
+						Regexp: `^/var/log/pods/(?P<namespace>[^_]+)_(?P<pod>[^_]+)_[^/]+/(?P<container>[^/]+)/`,
+					},
+				},
+				ServiceGroup: &ConfigAttribute{Exp: &ConfigExpression{Source: "var:namespace"}, Rename: "ze_deployment_name"},
+				Host:         &ConfigAttribute{Exp: &ConfigExpression{Source: "rattr:host.name"}, Rename: "host"},
+				Logbasename:  &ConfigAttribute{Exp: &ConfigExpression{Source: "var:container"}, Rename: "logbasename"},
+				Message:      &ConfigAttribute{Exp: &ConfigExpression{Source: "body"}},
+			},
+			{
+				ServiceGroup: &ConfigAttribute{Exp: lit("default"), Rename: "ze_deployment_name"},
+				Host:         &ConfigAttribute{Exp: &ConfigExpression{Source: "rattr:host.name"}, Rename: "host"},
+				Logbasename: &ConfigAttribute{
+					Exp:    op(CfgOpOr, &ConfigExpression{Source: "var:container"}, lit("unknown")),
+					Rename: "logbasename",
+				},
+				Message: &ConfigAttribute{Exp: &ConfigExpression{Source: "body"}},
+			},
+		},
+	}
+	// var sources must refer to the profile's own groups
+	require.Error(t, cfg.Validate())
+	cfg.Profiles[1].Logbasename.Exp = lit("unknown")
+	require.NoError(t, cfg.Validate())
+	m, err := newProfileMatcher(cfg)
+	require.NoError(t, err)
+
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr("host.name", "node1")
+	ils := rl.ScopeLogs().AppendEmpty()
+	lr := ils.LogRecords().AppendEmpty()
+	lr.Body().SetStr("GET /healthz 200")
+	lr.Attributes().PutStr("log.file.path", "/var/log/pods/prod_nginx-5d8f7c6b4-x2x9z_0f3a/nginx/0.log")
+
+	gen, req, err := m.MatchProfile(zap.NewNop(), rl, ils, lr)
+	require.NoError(t, err)
+	assert.Equal(t, "prod", gen.ServiceGroup)
+	assert.Equal(t, "nginx", gen.Logbasename)
+	assert.Equal(t, map[string]string{
+		"namespace": "prod",
+		"pod":       "nginx-5d8f7c6b4-x2x9z",
+		"container": "nginx",
+	}, req.Cfgs)
+
+	lr.Attributes().PutStr("log.file.path", "/var/log/syslog")
+	gen, req, err = m.MatchProfile(zap.NewNop(), rl, ils, lr)
+	require.NoError(t, err)
+	assert.Equal(t, "unknown", gen.Logbasename)
+	assert.Empty(t, req.Cfgs)
+}
@@ -112,6 +112,12 @@ type slLogFormatProcessorTelemetry struct {
 	timeoutTriggerSend   metric.Int64Counter
 	batchSendSize        metric.Int64Histogram
 	batchSendSizeBytes   metric.Int64Histogram
+
+	timestampParseFailures metric.Int64Counter
+	redactions             metric.Int64Counter
+	duplicatesSuppressed   metric.Int64Counter
+	recordsShed            metric.Int64Counter
+	recordsDropped         metric.Int64Counter
 }
 
 func newSlLogFormatProcessorTelemetry(set processor.Settings, useOtel bool) (*slLogFormatProcessorTelemetry, error) {
@@ -137,9 +143,111 @@ func newSlLogFormatProcessorTelemetry(set processor.Settings, useOtel bool) (*sl
 }
 
 func (bpt *slLogFormatProcessorTelemetry) createOtelMetrics(mp metric.MeterProvider) error {
+	if !bpt.useOtel {
+		return nil
+	}
+
+	var err error
+	meter := mp.Meter(scopeName)
+
+	bpt.timestampParseFailures, err = meter.Int64Counter(
+		processorhelper.BuildCustomMetricName(typeStr, "timestamp_parse_failures"),
+		metric.WithDescription("Number of log records whose profile timestamp did not match any layout"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return err
+	}
+
+	bpt.redactions, err = meter.Int64Counter(
+		processorhelper.BuildCustomMetricName(typeStr, "redactions"),
+		metric.WithDescription("Number of values masked by redaction rules"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return err
+	}
+
+	bpt.duplicatesSuppressed, err = meter.Int64Counter(
+		processorhelper.BuildCustomMetricName(typeStr, "duplicates_suppressed"),
+		metric.WithDescription("Number of log records suppressed as duplicates of an earlier message"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return err
+	}
+
+	bpt.recordsShed, err = meter.Int64Counter(
+		processorhelper.BuildCustomMetricName(typeStr, "records_shed"),
+		metric.WithDescription("Number of log records of a stream shed by the rate limit"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return err
+	}
+
+	bpt.recordsDropped, err = meter.Int64Counter(
+		processorhelper.BuildCustomMetricName(typeStr, "records_dropped"),
+		metric.WithDescription("Number of log records discarded by the drop rules or min_severity of a profile"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// withAttrs returns the processor attribute followed by attrs.
+func (bpt *slLogFormatProcessorTelemetry) withAttrs(attrs ...attribute.KeyValue) metric.MeasurementOption {
+	all := make([]attribute.KeyValue, 0, len(bpt.processorAttr)+len(attrs))
+	all = append(all, bpt.processorAttr...)
+	return metric.WithAttributes(append(all, attrs...)...)
+}
+
+// profileAttrs returns the attributes of metrics recorded for a profile.
+func (bpt *slLogFormatProcessorTelemetry) profileAttrs(profile int) metric.MeasurementOption {
+	return bpt.withAttrs(attribute.Int("profile", profile))
+}
+
+func (bpt *slLogFormatProcessorTelemetry) recordTimestampParseFailure(profile int) {
+	if bpt == nil || bpt.timestampParseFailures == nil {
+		return
+	}
+	bpt.timestampParseFailures.Add(bpt.exportCtx, 1, bpt.profileAttrs(profile))
+}
+
+func (bpt *slLogFormatProcessorTelemetry) recordRedactions(rule string, count int) {
+	if bpt == nil || bpt.redactions == nil || count == 0 {
+		return
+	}
+	bpt.redactions.Add(bpt.exportCtx, int64(count), bpt.withAttrs(attribute.String("rule", rule)))
+}
+
+func (bpt *slLogFormatProcessorTelemetry) recordDuplicatesSuppressed(count int) {
+	if bpt == nil || bpt.duplicatesSuppressed == nil {
+		return
+	}
+	bpt.duplicatesSuppressed.Add(bpt.exportCtx, int64(count), bpt.withAttrs())
+}
+
+func (bpt *slLogFormatProcessorTelemetry) recordRecordsShed(gen *ConfigResult) {
+	if bpt == nil || bpt.recordsShed == nil {
+		return
+	}
+	bpt.recordsShed.Add(bpt.exportCtx, 1, bpt.withAttrs(
+		attribute.String("service_group", gen.ServiceGroup),
+		attribute.String("host", gen.Host),
+		attribute.String("logbasename", gen.Logbasename)))
+}
+
+func (bpt *slLogFormatProcessorTelemetry) recordDropped(profile int, rule string) {
+	if bpt == nil || bpt.recordsDropped == nil {
+		return
+	}
+	bpt.recordsDropped.Add(bpt.exportCtx, 1, bpt.withAttrs(attribute.Int("profile", profile), attribute.String("rule", rule)))
+}
+
 func (bpt *slLogFormatProcessorTelemetry) record(trigger trigger, sent, bytes int64) {
 }
 
@@ -0,0 +1,44 @@
+package sllogformatprocessor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"go.opentelemetry.io/collector/processor/processorhelper"
+	"go.opentelemetry.io/collector/processor/processortest"
+)
+
+func newTestTelemetry(t *testing.T) (*slLogFormatProcessorTelemetry, *sdkmetric.ManualReader) {
+	reader := sdkmetric.NewManualReader()
+	set := processortest.NewNopSettings()
+	set.MeterProvider = sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	bpt, err := newSlLogFormatProcessorTelemetry(set, true)
+	require.NoError(t, err)
+	return bpt, reader
+}
+
+// counterValues returns the values of a counter by the value of attribute key.
+func counterValues(t *testing.T, reader *sdkmetric.ManualReader, name, key string) map[string]int64 {
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	ret := map[string]int64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != processorhelper.BuildCustomMetricName(typeStr, name) {
+				continue
+			}
+			sum, ok := m.Data.(metricdata.Sum[int64])
+			require.True(t, ok)
+			for _, dp := range sum.DataPoints {
+				val, _ := dp.Attributes.Value(attribute.Key(key))
+				ret[val.Emit()] += dp.Value
+			}
+		}
+	}
+	return ret
+}
@@ -0,0 +1,145 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sllogformatprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/sllogformatprocessor"
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+const (
+	defaultMultilineMaxLines     = 500
+	defaultMultilineMaxBytes     = 64 * 1024
+	defaultMultilineFlushTimeout = time.Second
+	// multilineSeparator joins the lines of a group, messages hold
+	// newlines escaped.
+	multilineSeparator = `\n`
+)
+
+// compiledMultiline is the compiled form of a ConfigMultiline.
+type compiledMultiline struct {
+	start        *regexp.Regexp
+	continuation *regexp.Regexp
+	maxLines     int
+	maxBytes     int
+	flushTimeout time.Duration
+}
+
+func compileMultiline(idx int, cfg *ConfigMultiline) (*compiledMultiline, error) {
+	if (cfg.Start == "") == (cfg.Continuation == "") {
+		return nil, fmt.Errorf("profile %d multiline must specify exactly one of start or continuation", idx)
+	}
+	if cfg.MaxLines < 0 || cfg.MaxBytes < 0 || cfg.FlushTimeout < 0 {
+		return nil, fmt.Errorf("profile %d multiline max_lines, max_bytes and flush_timeout must not be negative", idx)
+	}
+	ml := &compiledMultiline{
+		maxLines:     cfg.MaxLines,
+		maxBytes:     cfg.MaxBytes,
+		flushTimeout: cfg.FlushTimeout,
+	}
+	if ml.maxLines == 0 {
+		ml.maxLines = defaultMultilineMaxLines
+	}
+	if ml.maxBytes == 0 {
+		ml.maxBytes = defaultMultilineMaxBytes
+	}
+	if ml.flushTimeout == 0 {
+		ml.flushTimeout = defaultMultilineFlushTimeout
+	}
+	var err error
+	if cfg.Start != "" {
+		if ml.start, err = regexp.Compile(cfg.Start); err != nil {
+			return nil, fmt.Errorf("profile %d multiline has invalid start %s - %s", idx, cfg.Start, err.Error())
+		}
+	} else if ml.continuation, err = regexp.Compile(cfg.Continuation); err != nil {
+		return nil, fmt.Errorf("profile %d multiline has invalid continuation %s - %s", idx, cfg.Continuation, err.Error())
+	}
+	return ml, nil
+}
+
+// continues reports if a message belongs to the group before it.
+func (ml *compiledMultiline) continues(msg string) bool {
+	if ml.start != nil {
+		return !ml.start.MatchString(msg)
+	}
+	return ml.continuation.MatchString(msg)
+}
+
+// pendingRecord holds the first record of a group with the metadata of
+// its stream and the message merged so far.
+type pendingRecord struct {
+	profile  *compiledProfile
+	gen      *ConfigResult
+	reqBytes []byte
+	resource pcommon.Map
+	records  plog.LogRecordSlice
+	message  strings.Builder
+	lines    int
+	deadline time.Time
+	// key is the batch key of the fragments of a container log line.
+	key string
+}
+
+func newPendingRecord(rlAttr pcommon.Map, profile *compiledProfile, gen *ConfigResult, reqBytes []byte, lr plog.LogRecord) *pendingRecord {
+	group := &pendingRecord{
+		profile:  profile,
+		gen:      gen,
+		reqBytes: reqBytes,
+		resource: pcommon.NewMap(),
+		records:  plog.NewLogRecordSlice(),
+		lines:    1,
+	}
+	rlAttr.CopyTo(group.resource)
+	lr.MoveTo(group.records.AppendEmpty())
+	group.message.WriteString(gen.Message)
+	return group
+}
+
+// addMultiline adds a record of a profile with multiline settings to the
+// group of its stream. Continuation records are merged into the message
+// of the first record of the group and dropped.
+func (bl *batchLogs) addMultiline(key string, rlAttr pcommon.Map, profile *compiledProfile, gen *ConfigResult, reqBytes []byte, lr plog.LogRecord) {
+	ml := profile.multiline
+	group, ok := bl.multiline[key]
+	if ok && (!ml.continues(gen.Message) || group.message.Len()+len(multilineSeparator)+len(gen.Message) > ml.maxBytes) {
+		bl.flushMultiline(key, group)
+		ok = false
+	}
+	if ok {
+		group.message.WriteString(multilineSeparator)
+		group.message.WriteString(gen.Message)
+		group.lines++
+	} else {
+		group = newPendingRecord(rlAttr, profile, gen, reqBytes, lr)
+		bl.multiline[key] = group
+	}
+	group.deadline = timeNow().Add(ml.flushTimeout)
+	if group.lines >= ml.maxLines {
+		bl.flushMultiline(key, group)
+	}
+}
+
+// flushMultiline adds the record of a group with the merged message to
+// the batch.
+func (bl *batchLogs) flushMultiline(key string, group *pendingRecord) {
+	delete(bl.multiline, key)
+	group.gen.Message = group.message.String()
+	bl.addMessage(key, group.resource, group.profile, group.gen, group.reqBytes, group.records.At(0))
+}
@@ -0,0 +1,178 @@
+package sllogformatprocessor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/processor/processortest"
+	"go.uber.org/zap"
+)
+
+func multilineLogs(lines ...string) plog.Logs {
+	ld := resourceLogs(0)
+	ils := ld.ResourceLogs().At(0).ScopeLogs().At(0)
+	for _, line := range lines {
+		ils.LogRecords().AppendEmpty().Body().SetStr(line)
+	}
+	return ld
+}
+
+func sentMessages(t *testing.T, bl *batchLogs, sink *consumertest.LogsSink) []string {
+	sink.Reset()
+	_, _, err := bl.export(context.Background(), 0, false)
+	require.NoError(t, err)
+	var msgs []string
+	for _, ld := range sink.AllLogs() {
+		for i := 0; i < ld.ResourceLogs().Len(); i++ {
+			lrs := ld.ResourceLogs().At(i).ScopeLogs().At(0).LogRecords()
+			for j := 0; j < lrs.Len(); j++ {
+				msg, _ := lrs.At(j).Attributes().Get("sl_msg")
+				msgs = append(msgs, msg.Str())
+			}
+		}
+	}
+	return msgs
+}
+
+func TestMultilineStart(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 10, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	cfg := resourceConfig()
+	cfg.Profiles[0].Multiline = &ConfigMultiline{Start: `^\d{4}-\d{2}-\d{2} `, MaxLines: 4}
+	sink := new(consumertest.LogsSink)
+	bl, err := newBatchLogs(zap.NewNop(), cfg, sink)
+	require.NoError(t, err)
+
+	bl.add(multilineLogs(
+		"2024-03-01 ERROR request failed",
+		"java.lang.NullPointerException: null",
+		"    at com.example.Handler.handle(Handler.java:42)",
+		"2024-03-01 INFO next request",
+	))
+	bl.add(multilineLogs("    at com.example.Server.run(Server.java:7)"))
+	assert.Equal(t, []string{
+		`2024-03-01 ERROR request failed\njava.lang.NullPointerException: null\n    at com.example.Handler.handle(Handler.java:42)`,
+	}, sentMessages(t, bl, sink))
+
+	// the group is flushed once the timeout passes
+	bl.flush(false)
+	assert.Empty(t, sentMessages(t, bl, sink))
+	now = now.Add(defaultMultilineFlushTimeout)
+	bl.flush(false)
+	assert.Equal(t, []string{
+		`2024-03-01 INFO next request\n    at com.example.Server.run(Server.java:7)`,
+	}, sentMessages(t, bl, sink))
+
+	// max_lines
+	bl.add(multilineLogs("2024-03-01 ERROR a", "1", "2", "3", "4"))
+	bl.flush(true)
+	assert.Equal(t, []string{`2024-03-01 ERROR a\n1\n2\n3`, "4"}, sentMessages(t, bl, sink))
+}
+
+func TestMultilineContinuation(t *testing.T) {
+	cfg := resourceConfig()
+	cfg.Profiles[0].Multiline = &ConfigMultiline{Continuation: `^(\s|Traceback|\w+Error:)`, MaxBytes: 60}
+	cfg.Profiles[0].Format = CfgFormatEvent
+	sink := new(consumertest.LogsSink)
+	bl, err := newBatchLogs(zap.NewNop(), cfg, sink)
+	require.NoError(t, err)
+
+	ld := multilineLogs(
+		"worker crashed",
+		"Traceback (most recent call last):",
+		"  File \"app.py\", line 3",
+		"ValueError: bad",
+	)
+	lrs := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	for i := 0; i < lrs.Len(); i++ {
+		lrs.At(i).SetTimestamp(1709287200000000000)
+		lrs.At(i).SetSeverityNumber(plog.SeverityNumberError)
+	}
+	bl.add(ld)
+	bl.flush(true)
+	// the group is split at max_bytes, the event prefix is taken from the
+	// first record of each group
+	assert.Equal(t, []string{
+		`ze_tm=1709287200000,msg=2024-03-01T10:00:00Z ERROR worker crashed\nTraceback (most recent call last):`,
+		`ze_tm=1709287200000,msg=2024-03-01T10:00:00Z ERROR   File "app.py", line 3\nValueError: bad`,
+	}, sentMessages(t, bl, sink))
+}
+
+func TestMultilineFlushOnShutdown(t *testing.T) {
+	cfg := resourceConfig()
+	cfg.SendBatchSize = 100
+	cfg.Timeout = time.Hour
+	cfg.Profiles[0].Multiline = &ConfigMultiline{Start: `^\S`, FlushTimeout: time.Hour}
+	sink := new(consumertest.LogsSink)
+	bp, err := newBatchLogsProcessor(processortest.NewNopSettings(), sink, cfg, false)
+	require.NoError(t, err)
+	require.NoError(t, bp.Start(context.Background(), componenttest.NewNopHost()))
+	require.NoError(t, bp.ConsumeLogs(context.Background(), multilineLogs("panic: boom", "  goroutine 1")))
+	require.NoError(t, bp.Shutdown(context.Background()))
+
+	require.Equal(t, 1, sink.LogRecordCount())
+	msg, _ := sink.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes().Get("sl_msg")
+	assert.Equal(t, `panic: boom\n  goroutine 1`, msg.Str())
+}
+
+func TestMultilineFlushUnderLoad(t *testing.T) {
+	cfg := resourceConfig()
+	cfg.SendBatchSize = 1
+	cfg.Timeout = time.Hour
+	cfg.Profiles[0].Multiline = &ConfigMultiline{Start: `^\S`, FlushTimeout: 50 * time.Millisecond}
+	sink := new(consumertest.LogsSink)
+	bp, err := newBatchLogsProcessor(processortest.NewNopSettings(), sink, cfg, false)
+	require.NoError(t, err)
+	require.NoError(t, bp.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() { require.NoError(t, bp.Shutdown(context.Background())) }()
+
+	quiet := multilineLogs("panic: boom")
+	quiet.ResourceLogs().At(0).Resource().Attributes().PutStr("k8s.pod.name", "quiet")
+	require.NoError(t, bp.ConsumeLogs(context.Background(), quiet))
+
+	// each record of the busy stream is sent for size, which resets the batch timer
+	flushed := func() bool {
+		for _, ld := range sink.AllLogs() {
+			msg, _ := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes().Get("sl_msg")
+			if msg.Str() == "panic: boom" {
+				return true
+			}
+		}
+		return false
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for !flushed() {
+		require.True(t, time.Now().Before(deadline), "quiet stream was not flushed")
+		require.NoError(t, bp.ConsumeLogs(context.Background(), multilineLogs("GET /index.html")))
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestCompileMultilineErrors(t *testing.T) {
+	testcases := []struct {
+		name string
+		cfg  ConfigMultiline
+		err  string
+	}{
+		{name: "none", cfg: ConfigMultiline{}, err: "profile 0 multiline must specify exactly one of start or continuation"},
+		{name: "both", cfg: ConfigMultiline{Start: "a", Continuation: "b"}, err: "profile 0 multiline must specify exactly one of start or continuation"},
+		{name: "start", cfg: ConfigMultiline{Start: "("}, err: "profile 0 multiline has invalid start ("},
+		{name: "continuation", cfg: ConfigMultiline{Continuation: "["}, err: "profile 0 multiline has invalid continuation ["},
+		{name: "negative", cfg: ConfigMultiline{Start: "a", MaxLines: -1}, err: "must not be negative"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := resourceConfig()
+			cfg.Profiles[0].Multiline = &tc.cfg
+			assert.ErrorContains(t, cfg.Validate(), tc.err)
+		})
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sllogformatprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/sllogformatprocessor"
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	// defaultPseudonymLength is the number of hex digits of a pseudonym.
+	defaultPseudonymLength = 16
+	maxPseudonymLength     = 2 * sha256.Size
+)
+
+// loadPseudonymKey returns the key of the pseudonymize op from the
+// configuration or the key file, nil if neither is set.
+func loadPseudonymKey(cfg *ConfigPseudonymize) ([]byte, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	if cfg.Key != "" && cfg.KeyFile != "" {
+		return nil, errors.New("pseudonymize must specify only one of key or key_file")
+	}
+	if cfg.KeyFile == "" {
+		return []byte(cfg.Key), nil
+	}
+	data, err := os.ReadFile(cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pseudonymize key_file %s - %s", cfg.KeyFile, err.Error())
+	}
+	key := strings.TrimRight(string(data), "\r\n")
+	if key == "" {
+		return nil, fmt.Errorf("pseudonymize key_file %s is empty", cfg.KeyFile)
+	}
+	return []byte(key), nil
+}
+
+// compilePseudonymize returns the pseudonym of A, the first length hex
+// digits of its HMAC-SHA256 with the configured key after prefix.
+func compilePseudonymize(c *profileCompiler, exps []*compiledExp) (evalFunc, error) {
+	if len(c.pseudonymKey) == 0 {
+		return nil, fmt.Errorf("op %s requires pseudonymize key or key_file", CfgOpPseudonymize)
+	}
+	a := exps[0]
+	length, prefix := defaultPseudonymLength, ""
+	if len(exps) > 1 {
+		n, err := strconv.Atoi(exps[1].value)
+		if !exps[1].lit || err != nil || n < 1 || n > maxPseudonymLength {
+			return nil, fmt.Errorf("op %s expects a literal length from 1 to %d", CfgOpPseudonymize, maxPseudonymLength)
+		}
+		length = n
+	}
+	if len(exps) > 2 {
+		if !exps[2].lit {
+			return nil, fmt.Errorf("op %s expects a literal prefix", CfgOpPseudonymize)
+		}
+		prefix = exps[2].value
+	}
+	key := c.pseudonymKey
+	return func(p *Parser) string {
+		val := a.eval(p)
+		if val == "" {
+			return ""
+		}
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(val))
+		return prefix + hex.EncodeToString(mac.Sum(nil))[:length]
+	}, nil
+}
@@ -0,0 +1,114 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sllogformatprocessor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func pseudonymizeConfig(key *ConfigPseudonymize) *Config {
+	return &Config{
+		Pseudonymize: key,
+		Profiles: []ConfigProfile{
+			{
+				Match:        []*ConfigCondition{{Exp: &ConfigExpression{Source: "rattr:host.name"}}},
+				ServiceGroup: &ConfigAttribute{Exp: lit("default"), Rename: "ze_deployment_name"},
+				Host:         &ConfigAttribute{Exp: op(CfgOpPseudonymize, &ConfigExpression{Source: "rattr:host.name"}, lit("12"), lit("h-")), Rename: "host"},
+				Logbasename:  &ConfigAttribute{Exp: lit("app"), Rename: "logbasename"},
+				Labels:       []*ConfigAttribute{{Exp: op(CfgOpPseudonymize, &ConfigExpression{Source: "attr:user"}), Rename: "user"}},
+				Message:      &ConfigAttribute{Exp: &ConfigExpression{Source: "body"}},
+				Format:       CfgFormatMessage,
+			},
+		},
+	}
+}
+
+func TestPseudonymize(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "key")
+	require.NoError(t, os.WriteFile(keyFile, []byte("secret-key\n"), 0600))
+
+	testcases := []struct {
+		name string
+		key  *ConfigPseudonymize
+		host string
+		want string
+	}{
+		{name: "key", key: &ConfigPseudonymize{Key: "secret-key"}, host: "web-01", want: "h-9ec51fe1dee6"},
+		{name: "key_file", key: &ConfigPseudonymize{KeyFile: keyFile}, host: "web-01", want: "h-9ec51fe1dee6"},
+		{name: "other host", key: &ConfigPseudonymize{Key: "secret-key"}, host: "web-02", want: "h-2413123d8a8c"},
+		{name: "other key", key: &ConfigPseudonymize{Key: "other"}, host: "web-01", want: "h-69a6b79d7b88"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := pseudonymizeConfig(tc.key)
+			require.NoError(t, cfg.Validate())
+			ld := plog.NewLogs()
+			rl := ld.ResourceLogs().AppendEmpty()
+			rl.Resource().Attributes().PutStr("host.name", tc.host)
+			ils := rl.ScopeLogs().AppendEmpty()
+			lr := ils.LogRecords().AppendEmpty()
+			lr.Body().SetStr("login failed")
+			lr.Attributes().PutStr("user", "web-01")
+
+			gen, req, err := cfg.MatchProfile(zap.NewNop(), rl, ils, lr)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, gen.Host)
+			assert.Equal(t, tc.want, req.Ids["host"])
+			assert.Len(t, req.Cfgs["user"], defaultPseudonymLength)
+		})
+	}
+
+	cfg := pseudonymizeConfig(nil)
+	assert.ErrorContains(t, cfg.Validate(), "op pseudonymize requires pseudonymize key or key_file")
+	cfg.Pseudonymize = &ConfigPseudonymize{Key: "secret-key", KeyFile: keyFile}
+	assert.ErrorContains(t, cfg.Validate(), "pseudonymize must specify only one of key or key_file")
+	cfg.Pseudonymize = &ConfigPseudonymize{KeyFile: filepath.Join(t.TempDir(), "missing")}
+	assert.ErrorContains(t, cfg.Validate(), "failed to read pseudonymize key_file")
+	cfg.Pseudonymize = &ConfigPseudonymize{Key: "secret-key"}
+	cfg.Profiles[0].Host.Exp.Exps[1] = lit("65")
+	assert.ErrorContains(t, cfg.Validate(), "op pseudonymize expects a literal length from 1 to 64")
+}
+
+func TestPseudonymizeKeyHidden(t *testing.T) {
+	cfg := pseudonymizeConfig(&ConfigPseudonymize{Key: "secret-key"})
+	require.NoError(t, cfg.Validate())
+
+	conf := confmap.New()
+	require.NoError(t, conf.Marshal(cfg))
+	assert.Equal(t, "[REDACTED]", conf.Get("pseudonymize::key"))
+	assert.NotContains(t, fmt.Sprintf("%v %+v", cfg.Pseudonymize, *cfg.Pseudonymize), "secret-key")
+
+	core, logs := observer.New(zap.DebugLevel)
+	bl, err := newBatchLogs(zap.New(core), cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	ld := plog.NewLogs()
+	ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Body().SetStr("no host")
+	bl.add(ld)
+	require.Equal(t, 2, logs.Len(), "expected the failure and the dumped record")
+	for _, entry := range logs.All() {
+		assert.NotContains(t, entry.Message, "secret-key")
+	}
+}
@@ -0,0 +1,225 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sllogformatprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/sllogformatprocessor"
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+const (
+	CfgRateLimitDrop   string = "drop"
+	CfgRateLimitSample string = "sample"
+
+	defaultRateLimitSampleRatio    = 0.1
+	defaultRateLimitReportInterval = time.Minute
+)
+
+var cfgRateLimitModeMap map[string]int = map[string]int{
+	CfgRateLimitDrop:   0,
+	CfgRateLimitSample: 0,
+}
+
+// sampleFloat returns a random number in [0, 1) to sample records over
+// budget, tests replace it.
+var sampleFloat = rand.Float64
+
+// severityClass is the budget of records by severity, records of
+// WARN and above are never limited.
+type severityClass int
+
+const (
+	classDebug severityClass = iota
+	classInfo
+	numSeverityClasses
+)
+
+// classOf returns the budget of a severity and false for WARN and above.
+func classOf(sev plog.SeverityNumber) (severityClass, bool) {
+	switch {
+	case sev >= plog.SeverityNumberWarn:
+		return 0, false
+	case sev >= plog.SeverityNumberTrace && sev < plog.SeverityNumberInfo:
+		return classDebug, true
+	}
+	return classInfo, true
+}
+
+// rateBudget is the compiled form of a ConfigRateBudget.
+type rateBudget struct {
+	rate  float64
+	burst float64
+}
+
+func compileRateBudget(name string, cfg *ConfigRateBudget) (*rateBudget, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	if cfg.Rate <= 0 || cfg.Burst < 0 {
+		return nil, fmt.Errorf("rate_limit %s requires a positive rate and a burst that is not negative", name)
+	}
+	budget := &rateBudget{rate: cfg.Rate, burst: float64(cfg.Burst)}
+	if budget.burst == 0 {
+		budget.burst = math.Max(1, math.Ceil(cfg.Rate))
+	}
+	return budget, nil
+}
+
+// tokenBucket holds the tokens of a stream for one severity class.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// take refills the bucket for the time since the last record and takes a
+// token if there is one.
+func (b *tokenBucket) take(now time.Time, budget *rateBudget) bool {
+	b.tokens = math.Min(budget.burst, b.tokens+now.Sub(b.last).Seconds()*budget.rate)
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// shedStream counts the records of a stream shed since the last report.
+type shedStream struct {
+	serviceGroup string
+	host         string
+	logbasename  string
+	count        int
+}
+
+// rateLimiter applies the budgets of a ConfigRateLimit to each batch key.
+type rateLimiter struct {
+	budgets        [numSeverityClasses]*rateBudget
+	sample         bool
+	sampleRatio    float64
+	reportInterval time.Duration
+	nextReport     time.Time
+	buckets        map[string]*[numSeverityClasses]tokenBucket
+	shed           map[string]*shedStream
+}
+
+func newRateLimiter(cfg *ConfigRateLimit) (*rateLimiter, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	rl := &rateLimiter{
+		sampleRatio:    cfg.SampleRatio,
+		reportInterval: cfg.ReportInterval,
+		buckets:        make(map[string]*[numSeverityClasses]tokenBucket),
+		shed:           make(map[string]*shedStream),
+	}
+	var err error
+	if rl.budgets[classDebug], err = compileRateBudget("debug", cfg.Debug); err != nil {
+		return nil, err
+	}
+	if rl.budgets[classInfo], err = compileRateBudget("info", cfg.Info); err != nil {
+		return nil, err
+	}
+	switch cfg.Mode {
+	case "", CfgRateLimitDrop:
+	case CfgRateLimitSample:
+		rl.sample = true
+	default:
+		return nil, fmt.Errorf("invalid value %s for rate_limit mode, supported values %v", cfg.Mode, keysForMap(cfgRateLimitModeMap))
+	}
+	if rl.sampleRatio < 0 || rl.sampleRatio > 1 {
+		return nil, errors.New("rate_limit sample_ratio must be between 0 and 1")
+	}
+	if rl.sampleRatio == 0 {
+		rl.sampleRatio = defaultRateLimitSampleRatio
+	}
+	if rl.reportInterval < 0 {
+		return nil, errors.New("rate_limit report_interval must not be negative")
+	}
+	if rl.reportInterval == 0 {
+		rl.reportInterval = defaultRateLimitReportInterval
+	}
+	return rl, nil
+}
+
+// allow reports if a record of the stream with key is within the budget
+// of its severity class, or kept by sampling, and counts it as shed
+// otherwise.
+func (rl *rateLimiter) allow(now time.Time, key string, gen *ConfigResult, sev plog.SeverityNumber) bool {
+	class, limited := classOf(sev)
+	if !limited || rl.budgets[class] == nil {
+		return true
+	}
+	buckets, ok := rl.buckets[key]
+	if !ok {
+		buckets = &[numSeverityClasses]tokenBucket{}
+		for idx, budget := range rl.budgets {
+			if budget != nil {
+				buckets[idx] = tokenBucket{tokens: budget.burst, last: now}
+			}
+		}
+		rl.buckets[key] = buckets
+	}
+	if buckets[class].take(now, rl.budgets[class]) {
+		return true
+	}
+	if rl.sample && sampleFloat() < rl.sampleRatio {
+		return true
+	}
+	stream, ok := rl.shed[key]
+	if !ok {
+		stream = &shedStream{serviceGroup: gen.ServiceGroup, host: gen.Host, logbasename: gen.Logbasename}
+		rl.shed[key] = stream
+	}
+	stream.count++
+	return false
+}
+
+// report logs the records shed by each stream since the last report once
+// the report interval has passed, or if all is set, and forgets the
+// buckets that have refilled.
+func (rl *rateLimiter) report(now time.Time, log *zap.Logger, all bool) {
+	if rl.nextReport.IsZero() {
+		rl.nextReport = now.Add(rl.reportInterval)
+	}
+	if !all && now.Before(rl.nextReport) {
+		return
+	}
+	rl.nextReport = now.Add(rl.reportInterval)
+	for key, stream := range rl.shed {
+		log.Info("Rate limit shed log records",
+			zap.String("service_group", stream.serviceGroup),
+			zap.String("host", stream.host),
+			zap.String("logbasename", stream.logbasename),
+			zap.Int("shed", stream.count))
+		delete(rl.shed, key)
+	}
+	for key, buckets := range rl.buckets {
+		full := true
+		for idx, budget := range rl.budgets {
+			if budget != nil && buckets[idx].tokens+now.Sub(buckets[idx].last).Seconds()*budget.rate < budget.burst {
+				full = false
+			}
+		}
+		if full {
+			delete(rl.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sllogformatprocessor
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// severityLogs returns a record for each severity with the severity as message.
+func severityLogs(sevs ...plog.SeverityNumber) plog.Logs {
+	ld := resourceLogs(0)
+	ils := ld.ResourceLogs().At(0).ScopeLogs().At(0)
+	for _, sev := range sevs {
+		lr := ils.LogRecords().AppendEmpty()
+		lr.SetSeverityNumber(sev)
+		lr.Body().SetStr(sev.String())
+	}
+	return ld
+}
+
+func TestRateLimit(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 10, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	cfg := resourceConfig()
+	cfg.RateLimit = &ConfigRateLimit{Info: &ConfigRateBudget{Rate: 1, Burst: 2}}
+	sink := new(consumertest.LogsSink)
+	core, logs := observer.New(zap.InfoLevel)
+	bl, err := newBatchLogs(zap.New(core), cfg, sink)
+	require.NoError(t, err)
+	bpt, reader := newTestTelemetry(t)
+	bl.setTelemetry(bpt)
+
+	info, warn, debug := plog.SeverityNumberInfo, plog.SeverityNumberWarn, plog.SeverityNumberDebug
+	bl.add(severityLogs(info, plog.SeverityNumberUnspecified, info, warn, plog.SeverityNumberFatal, debug, debug))
+	assert.Equal(t, []string{"Info", "Unspecified", "Warn", "Fatal", "Debug", "Debug"}, sentMessages(t, bl, sink))
+
+	// the bucket refills at rate
+	now = now.Add(1500 * time.Millisecond)
+	bl.add(severityLogs(info, info, info))
+	assert.Equal(t, []string{"Info"}, sentMessages(t, bl, sink))
+	assert.Equal(t, map[string]int64{"nginx": 3}, counterValues(t, reader, "records_shed", "logbasename"))
+
+	bl.flush(false)
+	assert.Equal(t, 0, logs.Len())
+	now = now.Add(defaultRateLimitReportInterval)
+	bl.flush(false)
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, int64(3), logs.All()[0].ContextMap()["shed"])
+	assert.Equal(t, "node1", logs.All()[0].ContextMap()["host"])
+	assert.Empty(t, bl.rateLimit.shed)
+	assert.Empty(t, bl.rateLimit.buckets)
+}
+
+func TestRateLimitSample(t *testing.T) {
+	samples := []float64{0.05, 0.5, 0.09, 0.2}
+	sampleFloat = func() float64 {
+		ret := samples[0]
+		samples = samples[1:]
+		return ret
+	}
+	defer func() { sampleFloat = rand.Float64 }()
+
+	cfg := resourceConfig()
+	cfg.RateLimit = &ConfigRateLimit{Debug: &ConfigRateBudget{Rate: 0.001}, Mode: CfgRateLimitSample}
+	sink := new(consumertest.LogsSink)
+	bl, err := newBatchLogs(zap.NewNop(), cfg, sink)
+	require.NoError(t, err)
+
+	debug := plog.SeverityNumberDebug
+	bl.add(severityLogs(debug, debug, debug, debug, plog.SeverityNumberTrace2))
+	// the first record is within the burst, trace records share the debug budget
+	assert.Equal(t, []string{"Debug", "Debug", "Debug"}, sentMessages(t, bl, sink))
+	assert.Empty(t, samples)
+}
+
+func TestRateLimitConfig(t *testing.T) {
+	testcases := []struct {
+		cfg  ConfigRateLimit
+		want string
+	}{
+		{cfg: ConfigRateLimit{Info: &ConfigRateBudget{}}, want: "rate_limit info requires a positive rate and a burst that is not negative"},
+		{cfg: ConfigRateLimit{Debug: &ConfigRateBudget{Rate: 1, Burst: -1}}, want: "rate_limit debug requires a positive rate"},
+		{cfg: ConfigRateLimit{Mode: "block"}, want: "invalid value block for rate_limit mode"},
+		{cfg: ConfigRateLimit{SampleRatio: 2}, want: "rate_limit sample_ratio must be between 0 and 1"},
+		{cfg: ConfigRateLimit{ReportInterval: -time.Second}, want: "rate_limit report_interval must not be negative"},
+	}
+	for _, tc := range testcases {
+		cfg := resourceConfig()
+		cfg.RateLimit = &tc.cfg
+		assert.ErrorContains(t, cfg.Validate(), tc.want)
+	}
+}
@@ -0,0 +1,339 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sllogformatprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/sllogformatprocessor"
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/netip"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	CfgRedactEmail      string = "email"
+	CfgRedactIPv4       string = "ipv4"
+	CfgRedactIPv6       string = "ipv6"
+	CfgRedactCreditCard string = "credit_card"
+	CfgRedactAWSKey     string = "aws_key"
+	CfgRedactJWT        string = "jwt"
+	CfgRedactBearer     string = "bearer"
+
+	CfgRedactModeMask    string = "mask"
+	CfgRedactModePartial string = "partial"
+	CfgRedactModeHash    string = "hash"
+
+	defaultRedactMask = "[REDACTED]"
+	defaultRedactKeep = 4
+	// redactHashLen is the number of hex digits of the hash mode.
+	redactHashLen = 16
+)
+
+var cfgRedactDetectorMap map[string]int = map[string]int{
+	CfgRedactEmail:      0,
+	CfgRedactIPv4:       0,
+	CfgRedactIPv6:       0,
+	CfgRedactCreditCard: 0,
+	CfgRedactAWSKey:     0,
+	CfgRedactJWT:        0,
+	CfgRedactBearer:     0,
+}
+
+var cfgRedactModeMap map[string]int = map[string]int{
+	CfgRedactModeMask:    0,
+	CfgRedactModePartial: 0,
+	CfgRedactModeHash:    0,
+}
+
+// redactDetector finds the values of a built-in detector. Matches of
+// the regexp are replaced, or its first group if it has one, when check
+// is nil or accepts the match. check may shorten the match by returning
+// a smaller end.
+type redactDetector struct {
+	re    *regexp.Regexp
+	check func(s string, start, end int) (int, bool)
+}
+
+var redactDetectors map[string]redactDetector = map[string]redactDetector{
+	CfgRedactEmail: {re: regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9\-]+(?:\.[A-Za-z0-9\-]+)*\.[A-Za-z]{2,}`)},
+	CfgRedactIPv4: {
+		re: regexp.MustCompile(`\b(?:(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)\.){3}(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)\b`),
+	},
+	CfgRedactIPv6: {
+		re:    regexp.MustCompile(`(?i)[0-9a-f:]*:(?:\d{1,3}(?:\.\d{1,3}){3}|[0-9a-f:]*)`),
+		check: checkIPv6,
+	},
+	CfgRedactCreditCard: {
+		re:    regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`),
+		check: checkLuhn,
+	},
+	CfgRedactAWSKey: {re: regexp.MustCompile(`\b(?:AKIA|ASIA|AGPA|AIDA|AROA|AIPA|ANPA|ANVA|A3T[A-Z0-9])[A-Z0-9]{16}\b`)},
+	CfgRedactJWT:    {re: regexp.MustCompile(`\beyJ[A-Za-z0-9_\-]+\.eyJ[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]*`)},
+	CfgRedactBearer: {re: regexp.MustCompile(`(?i)\bbearer\s+([A-Za-z0-9\-._~+/]+=*)`)},
+}
+
+// checkIPv6 accepts candidates that are not part of a longer word and
+// parse as an IPv6 address, a trailing colon is left out.
+func checkIPv6(s string, start, end int) (int, bool) {
+	if start > 0 && isWordByte(s[start-1]) || end < len(s) && isWordByte(s[end]) {
+		return end, false
+	}
+	candidate := s[start:end]
+	addr, err := netip.ParseAddr(candidate)
+	if err != nil && strings.HasSuffix(candidate, ":") && !strings.HasSuffix(candidate, "::") {
+		end--
+		addr, err = netip.ParseAddr(s[start:end])
+	}
+	if err != nil || !addr.Is6() || addr.IsUnspecified() {
+		return end, false
+	}
+	return end, true
+}
+
+func isWordByte(b byte) bool {
+	return b == '_' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
+
+// checkLuhn accepts card numbers of 13 to 19 digits with a valid Luhn
+// check digit.
+func checkLuhn(s string, start, end int) (int, bool) {
+	sum, digits := 0, 0
+	for i := end - 1; i >= start; i-- {
+		if s[i] < '0' || s[i] > '9' {
+			continue
+		}
+		d := int(s[i] - '0')
+		if digits%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		digits++
+	}
+	return end, digits >= 13 && digits <= 19 && sum%10 == 0
+}
+
+// redactRule is the compiled form of a ConfigRedactRule.
+type redactRule struct {
+	name string
+	redactDetector
+	mode string
+	mask string
+	keep int
+	// key is the HMAC key of the hash mode.
+	key []byte
+}
+
+// redactRules are applied in order, the processor's rules before the
+// rules of a profile.
+type redactRules []*redactRule
+
+// compileRedactRules compiles rules, name prefixes errors. The hash mode
+// uses key, the key of the pseudonymize op.
+func compileRedactRules(name string, rules []*ConfigRedactRule, key []byte) (redactRules, error) {
+	var compiled redactRules
+	for idx, rule := range rules {
+		if rule == nil || (rule.Detector == "") == (rule.Regexp == "") {
+			return nil, fmt.Errorf("%s rule %d must specify exactly one of detector or regexp", name, idx)
+		}
+		cr := &redactRule{name: rule.Name, mode: rule.Mode, mask: rule.Mask, keep: rule.Keep}
+		if rule.Detector != "" {
+			detector, ok := redactDetectors[rule.Detector]
+			if !ok {
+				return nil, fmt.Errorf("%s rule %d has invalid detector %s, supported values %v", name, idx, rule.Detector, keysForMap(cfgRedactDetectorMap))
+			}
+			cr.redactDetector = detector
+			if cr.name == "" {
+				cr.name = rule.Detector
+			}
+		} else {
+			re, err := regexp.Compile(rule.Regexp)
+			if err != nil {
+				return nil, fmt.Errorf("%s rule %d has invalid regexp %s - %s", name, idx, rule.Regexp, err.Error())
+			}
+			cr.re = re
+			if cr.name == "" {
+				cr.name = "custom"
+			}
+		}
+		if cr.mode == "" {
+			cr.mode = CfgRedactModeMask
+		}
+		if _, ok := cfgRedactModeMap[cr.mode]; !ok {
+			return nil, fmt.Errorf("%s rule %d has invalid mode %s, supported values %v", name, idx, cr.mode, keysForMap(cfgRedactModeMap))
+		}
+		if cr.mode == CfgRedactModeHash {
+			if len(key) == 0 {
+				return nil, fmt.Errorf("%s rule %d mode hash requires pseudonymize key or key_file", name, idx)
+			}
+			cr.key = key
+		}
+		if cr.keep < 0 {
+			return nil, fmt.Errorf("%s rule %d keep must not be negative", name, idx)
+		}
+		if cr.keep == 0 && cr.mode == CfgRedactModePartial {
+			cr.keep = defaultRedactKeep
+		}
+		if cr.mask == "" {
+			cr.mask = defaultRedactMask
+		}
+		compiled = append(compiled, cr)
+	}
+	return compiled, nil
+}
+
+// replace redacts all values of the rule found in s and returns their number.
+func (r *redactRule) replace(s string) (string, int) {
+	locs := r.re.FindAllStringSubmatchIndex(s, -1)
+	if len(locs) == 0 {
+		return s, 0
+	}
+	var sb strings.Builder
+	count, last := 0, 0
+	for _, loc := range locs {
+		start, end := loc[0], loc[1]
+		if len(loc) > 2 {
+			start, end = loc[2], loc[3]
+		}
+		if start < 0 || start == end {
+			continue
+		}
+		if r.check != nil {
+			var ok bool
+			if end, ok = r.check(s, start, end); !ok {
+				continue
+			}
+		}
+		sb.WriteString(s[last:start])
+		sb.WriteString(r.redact(s[start:end]))
+		last = end
+		count++
+	}
+	if count == 0 {
+		return s, 0
+	}
+	sb.WriteString(s[last:])
+	return sb.String(), count
+}
+
+// redact returns the replacement of a value for the rule's mode.
+func (r *redactRule) redact(value string) string {
+	switch r.mode {
+	case CfgRedactModePartial:
+		n := utf8.RuneCountInString(value)
+		if n <= r.keep {
+			return strings.Repeat("*", n)
+		}
+		var sb strings.Builder
+		for idx, ch := range value {
+			if n <= r.keep {
+				sb.WriteString(value[idx:])
+				break
+			}
+			if ch == ' ' || ch == '-' {
+				// keep the grouping of card numbers
+				sb.WriteRune(ch)
+			} else {
+				sb.WriteByte('*')
+			}
+			n--
+		}
+		return sb.String()
+	case CfgRedactModeHash:
+		mac := hmac.New(sha256.New, r.key)
+		mac.Write([]byte(value))
+		return "hmac:" + hex.EncodeToString(mac.Sum(nil))[:redactHashLen]
+	}
+	return r.mask
+}
+
+// redactString applies the rules to s and adds the number of values
+// redacted by each rule to counts.
+func (rs redactRules) redactString(s string, counts []int) string {
+	for idx, rule := range rs {
+		var n int
+		s, n = rule.replace(s)
+		counts[idx] += n
+	}
+	return s
+}
+
+// redactMessage applies the rules to the message of a record and returns
+// the number of values redacted by each rule.
+func (rs redactRules) redactMessage(gen *ConfigResult) []int {
+	if len(rs) == 0 || gen.Message == "" {
+		return nil
+	}
+	counts := make([]int, len(rs))
+	gen.Message = rs.redactString(gen.Message, counts)
+	return counts
+}
+
+// redactContainerMessage redacts the complete message of a container log
+// record, which match leaves to reassembly.
+func (profile *compiledProfile) redactContainerMessage(gen *ConfigResult, telemetry *slLogFormatProcessorTelemetry) {
+	if profile.format != CfgFormatContainer {
+		return
+	}
+	for ruleIdx, count := range profile.redact.redactMessage(gen) {
+		telemetry.recordRedactions(profile.redact[ruleIdx].name, count)
+	}
+}
+
+// redactResult applies the rules to the ids and labels of a matched
+// record, and to its message if message is set, and returns the number
+// of values redacted by each rule.
+func (rs redactRules) redactResult(gen *ConfigResult, req *StreamTokenReq, message bool) []int {
+	if len(rs) == 0 {
+		return nil
+	}
+	counts := make([]int, len(rs))
+	// ids repeat the service group, host and logbasename, values are
+	// redacted and counted once
+	done := make(map[string]string)
+	apply := func(s string) string {
+		if s == "" {
+			return s
+		}
+		if redacted, ok := done[s]; ok {
+			return redacted
+		}
+		redacted := rs.redactString(s, counts)
+		done[s] = redacted
+		return redacted
+	}
+	gen.ServiceGroup = apply(gen.ServiceGroup)
+	gen.Host = apply(gen.Host)
+	gen.Logbasename = apply(gen.Logbasename)
+	req.Logbasename = apply(req.Logbasename)
+	for key, val := range req.Ids {
+		req.Ids[key] = apply(val)
+	}
+	for key, val := range req.Cfgs {
+		req.Cfgs[key] = apply(val)
+	}
+	for key, val := range gen.fields {
+		gen.fields[key] = apply(val)
+	}
+	if message {
+		gen.Message = apply(gen.Message)
+	}
+	return counts
+}
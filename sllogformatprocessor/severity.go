@@ -0,0 +1,200 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sllogformatprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/sllogformatprocessor"
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+// severityRule is the compiled form of a ConfigSeverityMapping.
+type severityRule struct {
+	severity plog.SeverityNumber
+	text     map[string]bool
+	ranges   [][2]int64
+	re       *regexp.Regexp
+}
+
+// severityRules maps text to severities, the first matching rule wins.
+type severityRules []*severityRule
+
+// parseSeverityName resolves a severity such as Error2, warning or 17.
+func parseSeverityName(name string) (plog.SeverityNumber, error) {
+	if num, err := strconv.Atoi(name); err == nil {
+		if num < int(plog.SeverityNumberTrace) || num > int(plog.SeverityNumberFatal4) {
+			return 0, fmt.Errorf("severity %d out of range 1 to 24", num)
+		}
+		return plog.SeverityNumber(num), nil
+	}
+	for text, num := range sevText2Num {
+		if strings.EqualFold(text, name) && num != plog.SeverityNumberUnspecified {
+			return num, nil
+		}
+	}
+	if num, ok := sevTextMap[strings.ToUpper(name)]; ok {
+		return num, nil
+	}
+	return 0, fmt.Errorf("invalid severity %s", name)
+}
+
+func compileSeverityRules(idx int, mappings []*ConfigSeverityMapping) (severityRules, error) {
+	var rules severityRules
+	for _, mapping := range mappings {
+		if mapping == nil {
+			continue
+		}
+		sev, err := parseSeverityName(mapping.Severity)
+		if err != nil {
+			return nil, fmt.Errorf("profile %d severity_map has %s", idx, err.Error())
+		}
+		rule := &severityRule{severity: sev, text: map[string]bool{}}
+		for _, text := range mapping.Text {
+			rule.text[strings.ToUpper(text)] = true
+		}
+		for _, r := range mapping.Ranges {
+			from, to, found := strings.Cut(r, "-")
+			if !found {
+				to = from
+			}
+			lo, err1 := strconv.ParseInt(strings.TrimSpace(from), 10, 64)
+			hi, err2 := strconv.ParseInt(strings.TrimSpace(to), 10, 64)
+			if err1 != nil || err2 != nil || lo > hi {
+				return nil, fmt.Errorf("profile %d severity_map has invalid range %s, expecting <from>-<to>", idx, r)
+			}
+			rule.ranges = append(rule.ranges, [2]int64{lo, hi})
+		}
+		if mapping.Regexp != "" {
+			if rule.re, err = regexp.Compile(mapping.Regexp); err != nil {
+				return nil, fmt.Errorf("profile %d severity_map has invalid regexp %s - %s", idx, mapping.Regexp, err.Error())
+			}
+		}
+		if len(rule.text) == 0 && len(rule.ranges) == 0 && rule.re == nil {
+			return nil, fmt.Errorf("profile %d severity_map %s requires text, ranges or regexp", idx, mapping.Severity)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func (rules severityRules) lookup(text string) (plog.SeverityNumber, bool) {
+	if len(rules) == 0 {
+		return plog.SeverityNumberUnspecified, false
+	}
+	upper := strings.ToUpper(text)
+	num, numErr := strconv.ParseInt(strings.TrimSpace(text), 10, 64)
+	for _, rule := range rules {
+		if rule.text[upper] {
+			return rule.severity, true
+		}
+		if numErr == nil {
+			for _, r := range rule.ranges {
+				if num >= r[0] && num <= r[1] {
+					return rule.severity, true
+				}
+			}
+		}
+		if rule.re != nil && rule.re.MatchString(text) {
+			return rule.severity, true
+		}
+	}
+	return plog.SeverityNumberUnspecified, false
+}
+
+// severityFromText maps text to a severity with the profile's rules,
+// then the OpenTelemetry severity names, common level names and syslog
+// severities, and finally HTTP status codes.
+func (rules severityRules) severityFromText(text string) plog.SeverityNumber {
+	if sev, ok := rules.lookup(text); ok {
+		return sev
+	}
+	if sev, ok := sevText2Num[text]; ok {
+		return sev
+	}
+	upper := strings.ToUpper(text)
+	if sev, ok := sevTextMap[upper]; ok {
+		return sev
+	}
+	if len(upper) == 3 {
+		// Interpret as HTTP status, also classes such as 5XX
+		switch upper[0] {
+		case '1', '2':
+			return plog.SeverityNumberInfo
+		case '3':
+			return plog.SeverityNumberDebug
+		case '4', '5':
+			return plog.SeverityNumberError
+		}
+	}
+	return plog.SeverityNumberUnspecified
+}
+
+// severityDetectLimit is the number of bytes at the start of a message
+// that are scanned for level tokens.
+const severityDetectLimit = 256
+
+// severityTokens matches level tokens, the syslog priority <PRI>, single
+// letter levels [W], klog headers such as E0301, level names and
+// level=<name> or "level":"<name>".
+var severityTokens = regexp.MustCompile(`<(\d{1,3})>|\[([TDIWEFC])\]|^([IWEF])\d{4} |\b(TRACE|DEBUG|INFO|INFORMATIONAL|NOTICE|WARN|WARNING|ERROR|ERR|CRIT|CRITICAL|ALERT|FATAL|EMERG|EMERGENCY|PANIC)\b|(?i:\blevel"?[=:]\s*"?([a-z]+))`)
+
+var severityLetters map[string]string = map[string]string{
+	"T": "TRACE",
+	"D": "DEBUG",
+	"I": "INFO",
+	"W": "WARN",
+	"E": "ERROR",
+	"F": "FATAL",
+	"C": "CRITICAL",
+}
+
+// detectSeverity returns the severity of the first level token in the
+// message. The token is mapped with the profile's rules first.
+func (rules severityRules) detectSeverity(msg string) plog.SeverityNumber {
+	if len(msg) > severityDetectLimit {
+		msg = msg[:severityDetectLimit]
+	}
+	for _, match := range severityTokens.FindAllStringSubmatch(msg, -1) {
+		var token, canonical string
+		switch {
+		case match[1] != "":
+			token = match[1]
+			pri, _ := strconv.Atoi(token)
+			if pri > 191 {
+				continue
+			}
+			// The syslog severity is the priority modulo 8
+			canonical = strconv.Itoa(pri % 8)
+		case match[2] != "":
+			token, canonical = match[2], severityLetters[match[2]]
+		case match[3] != "":
+			token, canonical = match[3], severityLetters[match[3]]
+		case match[4] != "":
+			token, canonical = match[4], match[4]
+		default:
+			token, canonical = match[5], strings.ToUpper(match[5])
+		}
+		if sev, ok := rules.lookup(token); ok {
+			return sev
+		}
+		if sev := rules.severityFromText(canonical); sev != plog.SeverityNumberUnspecified {
+			return sev
+		}
+	}
+	return plog.SeverityNumberUnspecified
+}
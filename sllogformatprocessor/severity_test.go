@@ -0,0 +1,143 @@
+package sllogformatprocessor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+func TestSeverityFromText(t *testing.T) {
+	rules, err := compileSeverityRules(0, []*ConfigSeverityMapping{
+		{Severity: "warn", Text: []string{"W", "degraded"}},
+		{Severity: "Error2", Ranges: []string{"500-599", "42"}},
+		{Severity: "21", Regexp: `^panic:`},
+	})
+	require.NoError(t, err)
+
+	testcases := []struct {
+		text string
+		want plog.SeverityNumber
+	}{
+		{text: "w", want: plog.SeverityNumberWarn},
+		{text: "Degraded", want: plog.SeverityNumberWarn},
+		{text: "503", want: plog.SeverityNumberError2},
+		{text: "42", want: plog.SeverityNumberError2},
+		{text: "panic: nil map", want: plog.SeverityNumberFatal},
+		{text: "Info3", want: plog.SeverityNumberInfo3},
+		{text: "warning", want: plog.SeverityNumberWarn},
+		{text: "Information", want: plog.SeverityNumberInfo},
+		{text: "404", want: plog.SeverityNumberError},
+		{text: "204", want: plog.SeverityNumberInfo},
+		{text: "5XX", want: plog.SeverityNumberError},
+		{text: "3xx", want: plog.SeverityNumberDebug},
+		{text: "abc", want: plog.SeverityNumberUnspecified},
+		{text: "999", want: plog.SeverityNumberUnspecified},
+	}
+	for _, tc := range testcases {
+		assert.Equal(t, tc.want, rules.severityFromText(tc.text), tc.text)
+	}
+	// without rules
+	assert.Equal(t, plog.SeverityNumberError, severityRules(nil).severityFromText("503"))
+}
+
+func TestCompileSeverityRulesErrors(t *testing.T) {
+	for _, mapping := range []*ConfigSeverityMapping{
+		{Severity: "loud", Text: []string{"x"}},
+		{Severity: "25", Text: []string{"x"}},
+		{Severity: "error"},
+		{Severity: "error", Ranges: []string{"5xx"}},
+		{Severity: "error", Ranges: []string{"599-500"}},
+		{Severity: "error", Regexp: "("},
+	} {
+		_, err := compileSeverityRules(0, []*ConfigSeverityMapping{mapping})
+		assert.Error(t, err, mapping)
+	}
+}
+
+func TestDetectSeverity(t *testing.T) {
+	rules, err := compileSeverityRules(0, []*ConfigSeverityMapping{
+		{Severity: "debug", Text: []string{"verbose"}},
+	})
+	require.NoError(t, err)
+
+	testcases := []struct {
+		msg  string
+		want plog.SeverityNumber
+	}{
+		{msg: "2024-03-01 10:00:00 ERROR db: connection refused", want: plog.SeverityNumberError},
+		{msg: "[W] cache almost full", want: plog.SeverityNumberWarn},
+		{msg: "<3>kernel: oops", want: plog.SeverityNumberError},
+		{msg: "<134>Oct 11 22:14:15 host app: started", want: plog.SeverityNumberInfo},
+		{msg: "E0301 10:00:00.123456 1 controller.go:42] sync failed", want: plog.SeverityNumberError},
+		{msg: `ts=2024-03-01 level=warn msg="slow"`, want: plog.SeverityNumberWarn},
+		{msg: `{"level":"debug","msg":"x"}`, want: plog.SeverityNumberDebug},
+		{msg: "level=verbose msg=x", want: plog.SeverityNumberDebug},
+		{msg: "WARNING then ERROR", want: plog.SeverityNumberWarn},
+		{msg: "errors are lowercase here", want: plog.SeverityNumberUnspecified},
+		{msg: "<999> INFO", want: plog.SeverityNumberInfo},
+	}
+	for _, tc := range testcases {
+		assert.Equal(t, tc.want, rules.detectSeverity(tc.msg), tc.msg)
+	}
+}
+
+func TestProfileSeverityMap(t *testing.T) {
+	cfg := &Config{
+		Profiles: []ConfigProfile{
+			{
+				ServiceGroup: &ConfigAttribute{Exp: lit("default"), Rename: "ze_deployment_name"},
+				Host:         &ConfigAttribute{Exp: lit("host1"), Rename: "host"},
+				Logbasename:  &ConfigAttribute{Exp: lit("app"), Rename: "logbasename"},
+				Severity:     &ConfigAttribute{Exp: &ConfigExpression{Source: "attr:status"}},
+				Message:      &ConfigAttribute{Exp: &ConfigExpression{Source: "body"}},
+				SeverityMap: []*ConfigSeverityMapping{
+					{Severity: "warn", Ranges: []string{"400-499"}},
+				},
+				Match: []*ConfigCondition{{Exp: &ConfigExpression{Source: "attr:status"}}},
+			},
+			{
+				ServiceGroup:   &ConfigAttribute{Exp: lit("default"), Rename: "ze_deployment_name"},
+				Host:           &ConfigAttribute{Exp: lit("host1"), Rename: "host"},
+				Logbasename:    &ConfigAttribute{Exp: lit("app"), Rename: "logbasename"},
+				Message:        &ConfigAttribute{Exp: &ConfigExpression{Source: "body"}},
+				SeverityDetect: true,
+				Format:         CfgFormatEvent,
+			},
+		},
+	}
+	require.NoError(t, cfg.Validate())
+	m, err := newProfileMatcher(cfg)
+	require.NoError(t, err)
+
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	ils := rl.ScopeLogs().AppendEmpty()
+	lr := ils.LogRecords().AppendEmpty()
+	lr.Body().SetStr("GET /missing")
+	lr.Attributes().PutStr("status", "404")
+	_, _, err = m.MatchProfile(zap.NewNop(), rl, ils, lr)
+	require.NoError(t, err)
+	assert.Equal(t, plog.SeverityNumberWarn, lr.SeverityNumber())
+
+	lr = ils.LogRecords().AppendEmpty()
+	lr.SetTimestamp(1)
+	lr.Body().SetStr("[E] disk full")
+	gen, _, err := m.MatchProfile(zap.NewNop(), rl, ils, lr)
+	require.NoError(t, err)
+	assert.Equal(t, plog.SeverityNumberError, lr.SeverityNumber())
+	assert.Equal(t, "ze_tm=0,msg=1970-01-01T00:00:00Z ERROR [E] disk full", gen.Message)
+
+	// the record's severity takes precedence
+	lr = ils.LogRecords().AppendEmpty()
+	lr.SetSeverityText("Debug")
+	lr.Body().SetStr("[E] disk full")
+	_, _, err = m.MatchProfile(zap.NewNop(), rl, ils, lr)
+	require.NoError(t, err)
+	assert.Equal(t, plog.SeverityNumberDebug, lr.SeverityNumber())
+
+	cfg.Profiles[0].SeverityMap[0].Severity = "loud"
+	assert.ErrorContains(t, cfg.Validate(), "profile 0 severity_map has invalid severity loud")
+}
@@ -43,6 +43,7 @@ type slLogFormatProcessor struct {
 	logger           *zap.Logger
 	exportCtx        context.Context
 	timer            *time.Timer
+	flushTicker      *time.Ticker
 	timeout          time.Duration
 	sendBatchSize    int
 	sendBatchMaxSize int
@@ -65,8 +66,17 @@ type batch interface {
 
 	// add item to the current batch
 	add(item any)
+
+	// flush adds items held back by the batch, such as partial multiline
+	// groups, to the current batch once they are due or if all is set
+	flush(all bool)
 }
 
+// flushInterval is the interval at which items held back by the batch
+// are checked, independent of the batch timeout which is reset by every
+// batch sent for size.
+var flushInterval = 100 * time.Millisecond
+
 var _ consumer.Traces = (*slLogFormatProcessor)(nil)
 var _ consumer.Metrics = (*slLogFormatProcessor)(nil)
 var _ consumer.Logs = (*slLogFormatProcessor)(nil)
@@ -114,6 +124,8 @@ func (bp *slLogFormatProcessor) Shutdown(context.Context) error {
 func (bp *slLogFormatProcessor) startProcessingCycle() {
 	defer bp.goroutines.Done()
 	bp.timer = time.NewTimer(bp.timeout)
+	bp.flushTicker = time.NewTicker(flushInterval)
+	defer bp.flushTicker.Stop()
 	for {
 		select {
 		case <-bp.shutdownC:
@@ -127,6 +139,7 @@ func (bp *slLogFormatProcessor) startProcessingCycle() {
 				}
 			}
 			// This is the close of the channel
+			bp.batch.flush(true)
 			if bp.batch.itemCount() > 0 {
 				// TODO: Set a timeout on sendTraces or
 				// make it cancellable using the context that Shutdown gets as a parameter
@@ -138,7 +151,11 @@ func (bp *slLogFormatProcessor) startProcessingCycle() {
 				continue
 			}
 			bp.processItem(item)
+		case <-bp.flushTicker.C:
+			bp.batch.flush(false)
+			bp.sendFullBatches()
 		case <-bp.timer.C:
+			bp.batch.flush(false)
 			if bp.batch.itemCount() > 0 {
 				bp.sendItems(triggerTimeout)
 			}
@@ -149,6 +166,12 @@ func (bp *slLogFormatProcessor) startProcessingCycle() {
 
 func (bp *slLogFormatProcessor) processItem(item any) {
 	bp.batch.add(item)
+	bp.sendFullBatches()
+}
+
+// sendFullBatches sends the batch while it holds at least sendBatchSize
+// items.
+func (bp *slLogFormatProcessor) sendFullBatches() {
 	sent := false
 	for bp.batch.itemCount() >= bp.sendBatchSize {
 		sent = true
@@ -201,5 +224,14 @@ func (bp *slLogFormatProcessor) ConsumeLogs(_ context.Context, ld plog.Logs) err
 
 // newBatchLogsProcessor creates a new batch processor that batches logs by size or with timeout
 func newBatchLogsProcessor(set processor.Settings, next consumer.Logs, cfg *Config, useOtel bool) (*slLogFormatProcessor, error) {
-	return newSlLogFormatProcessor(set, cfg, newBatchLogs(set.Logger, cfg, next), useOtel)
+	bl, err := newBatchLogs(set.Logger, cfg, next)
+	if err != nil {
+		return nil, err
+	}
+	bp, err := newSlLogFormatProcessor(set, cfg, bl, useOtel)
+	if err != nil {
+		return nil, err
+	}
+	bl.setTelemetry(bp.telemetry)
+	return bp, nil
 }
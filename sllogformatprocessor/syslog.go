@@ -0,0 +1,381 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sllogformatprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/sllogformatprocessor"
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	CfgSyslogFormat       string = "format"
+	CfgSyslogPriority     string = "priority"
+	CfgSyslogFacility     string = "facility"
+	CfgSyslogFacilityName string = "facility_name"
+	CfgSyslogSeverity     string = "severity"
+	CfgSyslogSeverityName string = "severity_name"
+	CfgSyslogVersion      string = "version"
+	CfgSyslogTimestamp    string = "timestamp"
+	CfgSyslogHostname     string = "hostname"
+	CfgSyslogAppname      string = "appname"
+	CfgSyslogProcid       string = "procid"
+	CfgSyslogMsgid        string = "msgid"
+	CfgSyslogSD           string = "sd"
+	CfgSyslogMessage      string = "message"
+)
+
+var cfgSyslogMap map[string]int = map[string]int{
+	CfgSyslogFormat:       0,
+	CfgSyslogPriority:     0,
+	CfgSyslogFacility:     0,
+	CfgSyslogFacilityName: 0,
+	CfgSyslogSeverity:     0,
+	CfgSyslogSeverityName: 0,
+	CfgSyslogVersion:      0,
+	CfgSyslogTimestamp:    0,
+	CfgSyslogHostname:     0,
+	CfgSyslogAppname:      0,
+	CfgSyslogProcid:       0,
+	CfgSyslogMsgid:        0,
+	CfgSyslogSD:           0,
+	CfgSyslogMessage:      0,
+}
+
+var syslogFacilityNames = []string{
+	"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news",
+	"uucp", "cron", "authpriv", "ftp", "ntp", "security", "console", "solaris-cron",
+	"local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7",
+}
+
+var syslogSeverityNames = []string{
+	"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug",
+}
+
+// validateSyslogField checks a field of the syslog source and operator,
+// structured data parameters are read with sd.<SD-ID>.<PARAM-NAME>.
+func validateSyslogField(field string) error {
+	if _, ok := cfgSyslogMap[field]; ok {
+		return nil
+	}
+	if rest, ok := strings.CutPrefix(field, CfgSyslogSD+"."); ok {
+		if id, param, ok := cutLast(rest, "."); ok && id != "" && param != "" {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid syslog field %s, supported values %v and sd.<id>.<param>", field, keysForMap(cfgSyslogMap))
+}
+
+func cutLast(s, sep string) (string, string, bool) {
+	if idx := strings.LastIndex(s, sep); idx >= 0 {
+		return s[:idx], s[idx+len(sep):], true
+	}
+	return s, "", false
+}
+
+// syslogMessage is a decoded RFC3164 or RFC5424 message, nil values are
+// empty.
+type syslogMessage struct {
+	format    string
+	priority  int
+	version   string
+	timestamp string
+	hostname  string
+	appname   string
+	procid    string
+	msgid     string
+	sd        string
+	sdParams  map[string]string
+	message   string
+}
+
+// field returns the value of a field validated by validateSyslogField.
+func (m *syslogMessage) field(name string) string {
+	if m == nil {
+		return ""
+	}
+	switch name {
+	case CfgSyslogFormat:
+		return m.format
+	case CfgSyslogPriority, CfgSyslogFacility, CfgSyslogFacilityName, CfgSyslogSeverity, CfgSyslogSeverityName:
+		if m.priority < 0 {
+			return ""
+		}
+		switch name {
+		case CfgSyslogPriority:
+			return strconv.Itoa(m.priority)
+		case CfgSyslogFacility:
+			return strconv.Itoa(m.priority / 8)
+		case CfgSyslogFacilityName:
+			return syslogFacilityNames[m.priority/8]
+		case CfgSyslogSeverity:
+			return strconv.Itoa(m.priority % 8)
+		default:
+			return syslogSeverityNames[m.priority%8]
+		}
+	case CfgSyslogVersion:
+		return m.version
+	case CfgSyslogTimestamp:
+		return m.timestamp
+	case CfgSyslogHostname:
+		return m.hostname
+	case CfgSyslogAppname:
+		return m.appname
+	case CfgSyslogProcid:
+		return m.procid
+	case CfgSyslogMsgid:
+		return m.msgid
+	case CfgSyslogSD:
+		return m.sd
+	case CfgSyslogMessage:
+		return m.message
+	}
+	return m.sdParams[strings.TrimPrefix(name, CfgSyslogSD+".")]
+}
+
+// parseSyslog decodes a syslog message with an optional <PRI> followed by
+// an RFC5424 or RFC3164 header. nil is returned for other input.
+func parseSyslog(in string) *syslogMessage {
+	m := &syslogMessage{priority: -1}
+	rest := in
+	if strings.HasPrefix(rest, "<") {
+		end := strings.IndexByte(rest, '>')
+		if end < 2 || end > 4 {
+			return nil
+		}
+		pri, err := strconv.Atoi(rest[1:end])
+		if err != nil || pri < 0 || pri > 191 {
+			return nil
+		}
+		m.priority = pri
+		rest = rest[end+1:]
+	}
+	if version, after, ok := strings.Cut(rest, " "); ok && version != "" && version[0] >= '1' && version[0] <= '9' && len(version) <= 2 {
+		if _, err := strconv.Atoi(version); err == nil && m.priority >= 0 {
+			m.format = "rfc5424"
+			m.version = version
+			if !m.parse5424(after) {
+				return nil
+			}
+			return m
+		}
+	}
+	m.format = "rfc3164"
+	if !m.parse3164(rest) {
+		return nil
+	}
+	return m
+}
+
+// nextSyslogField returns the next space separated field, - is nil.
+func nextSyslogField(in string) (string, string) {
+	field, rest, _ := strings.Cut(in, " ")
+	if field == "-" {
+		field = ""
+	}
+	return field, rest
+}
+
+func (m *syslogMessage) parse5424(in string) bool {
+	m.timestamp, in = nextSyslogField(in)
+	m.hostname, in = nextSyslogField(in)
+	m.appname, in = nextSyslogField(in)
+	m.procid, in = nextSyslogField(in)
+	m.msgid, in = nextSyslogField(in)
+	switch {
+	case strings.HasPrefix(in, "-"):
+		in = in[1:]
+	case strings.HasPrefix(in, "["):
+		end, ok := m.parseSD(in)
+		if !ok {
+			return false
+		}
+		m.sd = in[:end]
+		in = in[end:]
+	default:
+		return false
+	}
+	in = strings.TrimPrefix(in, " ")
+	m.message = strings.TrimPrefix(in, "\xEF\xBB\xBF")
+	return true
+}
+
+// parseSD decodes structured data elements [id param="value" ...] and
+// returns the offset after the last element.
+func (m *syslogMessage) parseSD(in string) (int, bool) {
+	m.sdParams = map[string]string{}
+	i := 0
+	for i < len(in) && in[i] == '[' {
+		i++
+		start := i
+		for i < len(in) && in[i] != ' ' && in[i] != ']' {
+			i++
+		}
+		id := in[start:i]
+		if id == "" || i >= len(in) {
+			return 0, false
+		}
+		for i < len(in) && in[i] == ' ' {
+			i++
+			start = i
+			for i < len(in) && in[i] != '=' {
+				i++
+			}
+			if i+1 >= len(in) || in[i+1] != '"' {
+				return 0, false
+			}
+			param := in[start:i]
+			var sb strings.Builder
+			for i += 2; i < len(in) && in[i] != '"'; i++ {
+				if in[i] == '\\' && i+1 < len(in) && strings.IndexByte(`"\]`, in[i+1]) >= 0 {
+					i++
+				}
+				sb.WriteByte(in[i])
+			}
+			if i >= len(in) {
+				return 0, false
+			}
+			i++
+			m.sdParams[id+"."+param] = sb.String()
+		}
+		if i >= len(in) || in[i] != ']' {
+			return 0, false
+		}
+		i++
+	}
+	return i, true
+}
+
+func (m *syslogMessage) parse3164(in string) bool {
+	switch {
+	case len(in) >= len(time.Stamp) && isSyslogStamp(in[:len(time.Stamp)]):
+		m.timestamp = in[:len(time.Stamp)]
+		in = strings.TrimPrefix(in[len(time.Stamp):], " ")
+	case m.priority < 0:
+		// Without a priority only the RFC3164 timestamp identifies syslog
+		return false
+	default:
+		// Some senders use RFC3339 timestamps in RFC3164 messages
+		field, rest, _ := strings.Cut(in, " ")
+		if _, err := time.Parse(time.RFC3339Nano, field); err != nil {
+			// A priority followed by the message only
+			m.message = in
+			return true
+		}
+		m.timestamp, in = field, rest
+	}
+	// The hostname is missing if the first field is already the tag
+	field, rest, _ := strings.Cut(in, " ")
+	if !strings.HasSuffix(field, ":") && !strings.Contains(field, "[") {
+		m.hostname, in = field, rest
+	}
+	tagEnd := strings.IndexAny(in, "[: ")
+	if tagEnd < 0 || in[tagEnd] == ' ' {
+		// No tag
+		m.message = in
+		return true
+	}
+	if in[tagEnd] == '[' {
+		end := strings.IndexByte(in, ']')
+		if end < 0 {
+			m.message = in
+			return true
+		}
+		m.procid = in[tagEnd+1 : end]
+		m.appname, in = in[:tagEnd], in[end+1:]
+	} else {
+		m.appname, in = in[:tagEnd], in[tagEnd:]
+	}
+	in = strings.TrimPrefix(in, ":")
+	m.message = strings.TrimPrefix(in, " ")
+	return true
+}
+
+func isSyslogStamp(in string) bool {
+	_, err := time.Parse(time.Stamp, in)
+	return err == nil
+}
+
+// evalSyslog returns a field of the body decoded as syslog message, the
+// body is decoded at most once per record.
+func (p *Parser) evalSyslog(field string) string {
+	if !p.syslogParsed {
+		p.syslogParsed = true
+		if body := evalValue("", p.Body); body != "" {
+			p.syslog = parseSyslog(body)
+		}
+	}
+	return p.syslog.field(field)
+}
+
+// profilePresets complete profiles for common log sources.
+var profilePresets map[string]func(profile ConfigProfile) ConfigProfile = map[string]func(profile ConfigProfile) ConfigProfile{
+	CfgPresetSyslog: syslogPreset,
+}
+
+// syslogPreset completes a profile for syslog messages in the body. The
+// profile only matches syslog messages and the parts that are not
+// configured are taken from the syslog header.
+func syslogPreset(profile ConfigProfile) ConfigProfile {
+	source := func(source string) *ConfigExpression {
+		return &ConfigExpression{Source: source}
+	}
+	field := func(name string) *ConfigExpression {
+		return source(CfgSourceSyslog + ":" + name)
+	}
+	profile.Match = append([]*ConfigCondition{{Exp: field(CfgSyslogFormat)}}, profile.Match...)
+	if profile.ServiceGroup == nil {
+		profile.ServiceGroup = &ConfigAttribute{Exp: source(CfgSourceLit + ":default"), Rename: "ze_deployment_name"}
+	}
+	if profile.Host == nil {
+		profile.Host = &ConfigAttribute{
+			Exp: &ConfigExpression{Op: CfgOpOr, Exps: []*ConfigExpression{
+				field(CfgSyslogHostname),
+				source(CfgSourceRattr + ":host.name"),
+			}},
+			Rename: "host",
+		}
+	}
+	if profile.Logbasename == nil {
+		profile.Logbasename = &ConfigAttribute{
+			Exp: &ConfigExpression{Op: CfgOpOr, Exps: []*ConfigExpression{
+				{Op: CfgOpLc, Exps: []*ConfigExpression{field(CfgSyslogAppname)}},
+				source(CfgSourceLit + ":syslog"),
+			}},
+			Rename: "logbasename",
+		}
+	}
+	if profile.Severity == nil {
+		// Messages without priority keep the severity text of the record
+		profile.Severity = &ConfigAttribute{
+			Exp: &ConfigExpression{Op: CfgOpOr, Exps: []*ConfigExpression{
+				field(CfgSyslogSeverity),
+				source(CfgSourceMeta + ":" + CfgMetaSeverityText),
+				source(CfgSourceLit + ":Unspecified"),
+			}},
+		}
+	}
+	if profile.Message == nil {
+		profile.Message = &ConfigAttribute{Exp: field(CfgSyslogMessage)}
+	}
+	if profile.Timestamp == nil {
+		profile.Timestamp = &ConfigTimestamp{
+			Exp:     field(CfgSyslogTimestamp),
+			Layouts: []string{"rfc3339", CfgLayoutSyslog},
+		}
+	}
+	return profile
+}
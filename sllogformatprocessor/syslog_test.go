@@ -0,0 +1,210 @@
+package sllogformatprocessor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+func TestParseSyslog(t *testing.T) {
+	testcases := []struct {
+		name string
+		in   string
+		want map[string]string
+	}{
+		{
+			name: "rfc3164",
+			in:   "<34>Oct 11 22:14:15 mymachine su[123]: 'su root' failed for lonvick on /dev/pts/8",
+			want: map[string]string{
+				"format": "rfc3164", "priority": "34", "facility": "4", "facility_name": "auth",
+				"severity": "2", "severity_name": "crit", "timestamp": "Oct 11 22:14:15",
+				"hostname": "mymachine", "appname": "su", "procid": "123",
+				"message": "'su root' failed for lonvick on /dev/pts/8",
+			},
+		},
+		{
+			name: "rfc3164 without hostname and pid",
+			in:   "<13>Feb  5 17:32:18 sshd: Accepted publickey",
+			want: map[string]string{
+				"format": "rfc3164", "severity": "5", "timestamp": "Feb  5 17:32:18",
+				"hostname": "", "appname": "sshd", "procid": "", "message": "Accepted publickey",
+			},
+		},
+		{
+			name: "rfc3164 without priority",
+			in:   "Feb  5 17:32:18 web1 cron[42]: job done",
+			want: map[string]string{
+				"format": "rfc3164", "priority": "", "severity": "", "hostname": "web1",
+				"appname": "cron", "procid": "42", "message": "job done",
+			},
+		},
+		{
+			name: "rfc3164 rfc3339 timestamp",
+			in:   "<30>2024-03-01T10:00:00Z web1 app: started",
+			want: map[string]string{
+				"format": "rfc3164", "timestamp": "2024-03-01T10:00:00Z", "hostname": "web1",
+				"appname": "app", "message": "started",
+			},
+		},
+		{
+			name: "priority only",
+			in:   "<14>plain message",
+			want: map[string]string{"format": "rfc3164", "severity": "6", "timestamp": "", "message": "plain message"},
+		},
+		{
+			name: "rfc5424",
+			in: `<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 ` +
+				`[exampleSDID@32473 iut="3" eventSource="Application" eventID="1011"][ex@1 q="a \"b\" \] c"] ` +
+				"\xEF\xBB\xBFAn application event",
+			want: map[string]string{
+				"format": "rfc5424", "version": "1", "facility_name": "local4", "severity_name": "notice",
+				"timestamp": "2003-10-11T22:14:15.003Z", "hostname": "mymachine.example.com",
+				"appname": "evntslog", "procid": "", "msgid": "ID47",
+				"sd":                           `[exampleSDID@32473 iut="3" eventSource="Application" eventID="1011"][ex@1 q="a \"b\" \] c"]`,
+				"sd.exampleSDID@32473.eventID": "1011",
+				"sd.ex@1.q":                    `a "b" ] c`,
+				"sd.ex@1.missing":              "",
+				"message":                      "An application event",
+			},
+		},
+		{
+			name: "rfc5424 nil values",
+			in:   "<14>1 - - - - - -",
+			want: map[string]string{
+				"format": "rfc5424", "timestamp": "", "hostname": "", "appname": "", "sd": "", "message": "",
+			},
+		},
+		{
+			name: "not syslog",
+			in:   "just some text",
+			want: map[string]string{"format": "", "message": ""},
+		},
+		{
+			name: "rfc3339 timestamp without priority",
+			in:   "2023-10-06T00:17:09.669794202Z stdout F message",
+			want: map[string]string{"format": "", "timestamp": "", "message": ""},
+		},
+		{
+			name: "invalid priority",
+			in:   "<192>Oct 11 22:14:15 host app: x",
+			want: map[string]string{"format": ""},
+		},
+		{
+			name: "invalid structured data",
+			in:   `<14>1 - - - - - [id a=b] x`,
+			want: map[string]string{"format": ""},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := parseSyslog(tc.in)
+			for field, want := range tc.want {
+				assert.Equal(t, want, m.field(field), field)
+			}
+		})
+	}
+}
+
+func TestSyslogOperatorAndSource(t *testing.T) {
+	lr := plog.NewLogRecord()
+	lr.Body().SetStr("<11>Mar  7 04:02:16 db1 postgres[88]: connection refused")
+	lr.Attributes().PutStr("raw", "<165>1 - host2 app - - [meta@1 env=\"prod\"] hello")
+	p := &Parser{Log: zap.NewNop(), Body: lr.Body(), Attr: lr.Attributes(), Record: lr}
+
+	testcases := []struct {
+		name string
+		exp  *ConfigExpression
+		want string
+	}{
+		{name: "source appname", exp: &ConfigExpression{Source: "syslog:appname"}, want: "postgres"},
+		{name: "source severity name", exp: &ConfigExpression{Source: "syslog:severity_name"}, want: "err"},
+		{name: "op hostname", exp: op(CfgOpSyslog, &ConfigExpression{Source: "attr:raw"}, lit("hostname")), want: "host2"},
+		{name: "op structured data", exp: op(CfgOpSyslog, &ConfigExpression{Source: "attr:raw"}, lit("sd.meta@1.env")), want: "prod"},
+		{name: "op not syslog", exp: op(CfgOpSyslog, lit("text"), lit("message")), want: ""},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			exp, err := testCompiler(t).compileExp(tc.exp)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, exp.eval(p))
+		})
+	}
+
+	for _, exp := range []*ConfigExpression{
+		{Source: "syslog"},
+		{Source: "syslog:pid"},
+		{Source: "syslog:sd.noparam"},
+		op(CfgOpSyslog, &ConfigExpression{Source: "body"}),
+		op(CfgOpSyslog, &ConfigExpression{Source: "body"}, lit("pid")),
+		op(CfgOpSyslog, &ConfigExpression{Source: "body"}, &ConfigExpression{Source: "body"}),
+	} {
+		_, err := testCompiler(t).compileExp(exp)
+		assert.Error(t, err)
+	}
+}
+
+func TestProfilePresetSyslog(t *testing.T) {
+	now := timeNow
+	timeNow = func() time.Time { return time.Date(2024, time.March, 10, 0, 0, 0, 0, time.UTC) }
+	defer func() { timeNow = now }()
+
+	cfg := &Config{
+		Profiles: []ConfigProfile{
+			{
+				Preset: CfgPresetSyslog,
+				Tz:     &ConfigAttribute{Exp: lit("UTC")},
+				Format: CfgFormatMessage,
+			},
+		},
+	}
+	require.NoError(t, cfg.Validate())
+	m, err := newProfileMatcher(cfg)
+	require.NoError(t, err)
+
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr("host.name", "collector1")
+	ils := rl.ScopeLogs().AppendEmpty()
+	lr := ils.LogRecords().AppendEmpty()
+
+	lr.Body().SetStr("<11>Mar  7 04:02:16 db1 Postgres[88]: connection refused")
+	gen, req, err := m.MatchProfile(zap.NewNop(), rl, ils, lr)
+	require.NoError(t, err)
+	assert.Equal(t, "db1", gen.Host)
+	assert.Equal(t, "default", gen.ServiceGroup)
+	assert.Equal(t, "postgres", req.Logbasename)
+	assert.Equal(t, "connection refused", gen.Message)
+	assert.Equal(t, plog.SeverityNumberError, lr.SeverityNumber())
+	assert.Equal(t, time.Date(2024, time.March, 7, 4, 2, 16, 0, time.UTC), lr.Timestamp().AsTime())
+
+	// the record's severity and the resource host are used as fallback
+	lr = ils.LogRecords().AppendEmpty()
+	lr.SetSeverityText("WARN")
+	lr.Body().SetStr("Mar  7 04:02:16 cron: job done")
+	gen, req, err = m.MatchProfile(zap.NewNop(), rl, ils, lr)
+	require.NoError(t, err)
+	assert.Equal(t, "collector1", gen.Host)
+	assert.Equal(t, "cron", req.Logbasename)
+	assert.Equal(t, plog.SeverityNumberWarn, lr.SeverityNumber())
+
+	lr.Body().SetStr("not a syslog message")
+	_, _, err = m.MatchProfile(zap.NewNop(), rl, ils, lr)
+	assert.Error(t, err)
+
+	// configured parts are kept
+	cfg.Profiles[0].Logbasename = &ConfigAttribute{Exp: lit("system"), Rename: "logbasename"}
+	m, err = newProfileMatcher(cfg)
+	require.NoError(t, err)
+	lr.Body().SetStr("<14>Mar  7 04:02:16 web1 nginx: ok")
+	_, req, err = m.MatchProfile(zap.NewNop(), rl, ils, lr)
+	require.NoError(t, err)
+	assert.Equal(t, "system", req.Logbasename)
+	assert.Len(t, cfg.Profiles[0].Match, 0)
+
+	cfg.Profiles[0].Preset = "windows"
+	assert.ErrorContains(t, cfg.Validate(), "profile 0 invalid value windows for preset")
+}
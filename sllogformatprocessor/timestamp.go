@@ -0,0 +1,257 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sllogformatprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/sllogformatprocessor"
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	// Embed the zone database, collector images often have none.
+	_ "time/tzdata"
+)
+
+const (
+	CfgLayoutSyslog  string = "syslog"
+	CfgLayoutEpoch   string = "epoch"
+	CfgLayoutEpochS  string = "epoch_s"
+	CfgLayoutEpochMs string = "epoch_ms"
+	CfgLayoutEpochUs string = "epoch_us"
+	CfgLayoutEpochNs string = "epoch_ns"
+)
+
+// cfgLayoutMap holds the named layouts that map to golang layouts.
+var cfgLayoutMap map[string]string = map[string]string{
+	"rfc3339":     time.RFC3339Nano,
+	"rfc3339nano": time.RFC3339Nano,
+	"rfc1123":     time.RFC1123,
+	"rfc1123z":    time.RFC1123Z,
+	"rfc822":      time.RFC822,
+	"rfc822z":     time.RFC822Z,
+	"rfc850":      time.RFC850,
+	"ansic":       time.ANSIC,
+	"unixdate":    time.UnixDate,
+	"rubydate":    time.RubyDate,
+	"datetime":    time.DateTime,
+}
+
+// timeNow returns the current time, replaced in tests.
+var timeNow = time.Now
+
+const (
+	// maxCachedZones and maxCachedZoneErrors bound the zone names held by
+	// a zoneCache, as the names are read from log records.
+	maxCachedZones      = 64
+	maxCachedZoneErrors = 16
+)
+
+// zoneCache holds the locations loaded for zone names, or the error if
+// the name is not a zone. Names beyond the limits are loaded for every
+// lookup.
+type zoneCache struct {
+	mu     sync.Mutex
+	zones  map[string]*time.Location
+	errors map[string]error
+}
+
+// location returns the location of an IANA zone name such as
+// Europe/Berlin. first is set for the call that added the name to the
+// cache, so that a failure is reported once while it is cached.
+func (zc *zoneCache) location(name string) (loc *time.Location, first bool, err error) {
+	zc.mu.Lock()
+	loc, ok := zc.zones[name]
+	if !ok {
+		err, ok = zc.errors[name]
+	}
+	zc.mu.Unlock()
+	if ok {
+		return loc, false, err
+	}
+	loc, err = time.LoadLocation(name)
+	zc.mu.Lock()
+	defer zc.mu.Unlock()
+	switch {
+	case err == nil && len(zc.zones) < maxCachedZones:
+		if zc.zones == nil {
+			zc.zones = make(map[string]*time.Location)
+		}
+		_, ok = zc.zones[name]
+		zc.zones[name] = loc
+	case err != nil && len(zc.errors) < maxCachedZoneErrors:
+		if zc.errors == nil {
+			zc.errors = make(map[string]error)
+		}
+		_, ok = zc.errors[name]
+		zc.errors[name] = err
+	default:
+		return loc, false, err
+	}
+	return loc, !ok, err
+}
+
+// timestampLayout parses a timestamp, loc is used for timestamps without
+// offset.
+type timestampLayout func(value string, loc *time.Location) (time.Time, error)
+
+// compileTimestampLayout resolves a named layout, a strftime layout with %
+// directives or a golang layout.
+func compileTimestampLayout(layout string) (timestampLayout, error) {
+	switch strings.ToLower(layout) {
+	case CfgLayoutSyslog:
+		return parseSyslogTimestamp, nil
+	case CfgLayoutEpoch:
+		return func(value string, _ *time.Location) (time.Time, error) {
+			return parseEpoch(value, 0)
+		}, nil
+	case CfgLayoutEpochS:
+		return epochLayout(time.Second), nil
+	case CfgLayoutEpochMs:
+		return epochLayout(time.Millisecond), nil
+	case CfgLayoutEpochUs:
+		return epochLayout(time.Microsecond), nil
+	case CfgLayoutEpochNs:
+		return epochLayout(time.Nanosecond), nil
+	}
+	goLayout, ok := cfgLayoutMap[strings.ToLower(layout)]
+	if !ok {
+		goLayout = layout
+		if strings.Contains(layout, "%") {
+			var err error
+			if goLayout, err = strftimeToLayout(layout); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if probe := time.Date(2001, time.February, 3, 4, 5, 6, 0, time.UTC); probe.Format(goLayout) == goLayout {
+		return nil, fmt.Errorf("timestamp layout %s has no time elements", layout)
+	}
+	return func(value string, loc *time.Location) (time.Time, error) {
+		return time.ParseInLocation(goLayout, value, loc)
+	}, nil
+}
+
+func epochLayout(unit time.Duration) timestampLayout {
+	return func(value string, _ *time.Location) (time.Time, error) {
+		return parseEpoch(value, unit)
+	}
+}
+
+// parseEpoch parses a number of units since the epoch with an optional
+// fraction, a unit of 0 picks seconds, milliseconds, microseconds or
+// nanoseconds from the number of integer digits.
+func parseEpoch(value string, unit time.Duration) (time.Time, error) {
+	digits, fraction, _ := strings.Cut(value, ".")
+	whole, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil || whole < 0 || strings.HasPrefix(digits, "+") {
+		return time.Time{}, fmt.Errorf("invalid epoch %q", value)
+	}
+	var frac int64
+	if fraction != "" {
+		// Nanoseconds of a unit, further digits are dropped
+		fraction = (fraction + "000000000")[:9]
+		if frac, err = strconv.ParseInt(fraction, 10, 64); err != nil || frac < 0 || strings.HasPrefix(fraction, "+") {
+			return time.Time{}, fmt.Errorf("invalid epoch %q", value)
+		}
+	}
+	if unit == 0 {
+		switch {
+		case len(digits) <= 10:
+			unit = time.Second
+		case len(digits) <= 13:
+			unit = time.Millisecond
+		case len(digits) <= 16:
+			unit = time.Microsecond
+		default:
+			unit = time.Nanosecond
+		}
+	}
+	if whole > math.MaxInt64/int64(unit)-1 {
+		return time.Time{}, fmt.Errorf("epoch %q out of range", value)
+	}
+	return time.Unix(0, whole*int64(unit)+frac*int64(unit)/int64(time.Second)).UTC(), nil
+}
+
+// parseSyslogTimestamp parses RFC3164 timestamps such as Mar  7 04:02:16,
+// which have no year. The current year is used unless that puts the
+// timestamp more than a day in the future, as for December logs read in
+// January, then the previous year is used.
+func parseSyslogTimestamp(value string, loc *time.Location) (time.Time, error) {
+	ts, err := time.ParseInLocation(time.Stamp, value, loc)
+	if err != nil {
+		return ts, err
+	}
+	now := timeNow().In(loc)
+	ts = ts.AddDate(now.Year(), 0, 0)
+	if ts.After(now.Add(24 * time.Hour)) {
+		ts = ts.AddDate(-1, 0, 0)
+	}
+	return ts, nil
+}
+
+// strftimeDirectives maps strftime directives to golang layout elements.
+var strftimeDirectives map[byte]string = map[byte]string{
+	'Y': "2006",
+	'y': "06",
+	'm': "01",
+	'd': "02",
+	'e': "_2",
+	'j': "002",
+	'H': "15",
+	'I': "03",
+	'M': "04",
+	'S': "05",
+	'L': ".000",
+	'f': ".000000",
+	'N': ".000000000",
+	'p': "PM",
+	'b': "Jan",
+	'h': "Jan",
+	'B': "January",
+	'a': "Mon",
+	'A': "Monday",
+	'z': "-0700",
+	'Z': "MST",
+	'T': "15:04:05",
+	'D': "01/02/06",
+	'F': "2006-01-02",
+	'R': "15:04",
+	'%': "%",
+}
+
+func strftimeToLayout(layout string) (string, error) {
+	var sb strings.Builder
+	for idx := 0; idx < len(layout); idx++ {
+		if layout[idx] != '%' {
+			sb.WriteByte(layout[idx])
+			continue
+		}
+		if idx+1 >= len(layout) {
+			return "", fmt.Errorf("timestamp layout %s ends with %%", layout)
+		}
+		idx++
+		elem, ok := strftimeDirectives[layout[idx]]
+		if !ok {
+			return "", fmt.Errorf("timestamp layout %s has unsupported directive %%%c", layout, layout[idx])
+		}
+		if strings.HasPrefix(elem, ".") && (strings.HasSuffix(sb.String(), ".") || strings.HasSuffix(sb.String(), ",")) {
+			// %S.%L, the layout already holds the separator
+			elem = elem[1:]
+		}
+		sb.WriteString(elem)
+	}
+	return sb.String(), nil
+}
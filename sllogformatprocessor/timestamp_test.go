@@ -0,0 +1,192 @@
+package sllogformatprocessor
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestTimestampLayouts(t *testing.T) {
+	now := time.Date(2024, time.March, 10, 12, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+	loc := time.FixedZone("UTC+2", 2*3600)
+
+	testcases := []struct {
+		layout string
+		value  string
+		want   time.Time
+	}{
+		{layout: "rfc3339", value: "2024-03-01T10:00:00.123Z", want: time.Date(2024, time.March, 1, 10, 0, 0, 123000000, time.UTC)},
+		{layout: "RFC3339", value: "2024-03-01T10:00:00+01:00", want: time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)},
+		{layout: "rfc1123z", value: "Fri, 01 Mar 2024 10:00:00 +0000", want: time.Date(2024, time.March, 1, 10, 0, 0, 0, time.UTC)},
+		{layout: "2006-01-02 15:04:05", value: "2024-03-01 10:00:00.5", want: time.Date(2024, time.March, 1, 8, 0, 0, 500000000, time.UTC)},
+		{layout: "%Y-%m-%d %H:%M:%S.%L %z", value: "2024-03-01 10:00:00.250 +0000", want: time.Date(2024, time.March, 1, 10, 0, 0, 250000000, time.UTC)},
+		{layout: "%d/%b/%Y:%T %z", value: "01/Mar/2024:10:00:00 -0700", want: time.Date(2024, time.March, 1, 17, 0, 0, 0, time.UTC)},
+		{layout: "epoch_s", value: "1709287200.5", want: time.Date(2024, time.March, 1, 10, 0, 0, 500000000, time.UTC)},
+		{layout: "epoch_ms", value: "1709287200123", want: time.Date(2024, time.March, 1, 10, 0, 0, 123000000, time.UTC)},
+		{layout: "epoch_us", value: "1709287200123456", want: time.Date(2024, time.March, 1, 10, 0, 0, 123456000, time.UTC)},
+		{layout: "epoch_ns", value: "1709287200123456789", want: time.Date(2024, time.March, 1, 10, 0, 0, 123456789, time.UTC)},
+		{layout: "epoch", value: "1709287200", want: time.Date(2024, time.March, 1, 10, 0, 0, 0, time.UTC)},
+		{layout: "epoch", value: "1709287200123", want: time.Date(2024, time.March, 1, 10, 0, 0, 123000000, time.UTC)},
+		{layout: "syslog", value: "Mar  1 10:00:00", want: time.Date(2024, time.March, 1, 8, 0, 0, 0, time.UTC)},
+		{layout: "syslog", value: "Dec 31 23:00:00", want: time.Date(2023, time.December, 31, 21, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.layout+" "+tc.value, func(t *testing.T) {
+			parse, err := compileTimestampLayout(tc.layout)
+			require.NoError(t, err)
+			ts, err := parse(tc.value, loc)
+			require.NoError(t, err)
+			assert.True(t, tc.want.Equal(ts), "got %s", ts)
+		})
+	}
+
+	for _, layout := range []string{"%Y-%q", "%Y %", "no elements"} {
+		_, err := compileTimestampLayout(layout)
+		assert.Error(t, err, layout)
+	}
+	parse, err := compileTimestampLayout("epoch")
+	require.NoError(t, err)
+	for _, value := range []string{"abc", "-1", "1.-5", "99999999999999999999"} {
+		_, err := parse(value, loc)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestProfileTimestamp(t *testing.T) {
+	cfg := &Config{
+		Profiles: []ConfigProfile{
+			{
+				ServiceGroup: &ConfigAttribute{Exp: lit("default"), Rename: "ze_deployment_name"},
+				Host:         &ConfigAttribute{Exp: lit("host1"), Rename: "host"},
+				Logbasename:  &ConfigAttribute{Exp: lit("app"), Rename: "logbasename"},
+				Message:      &ConfigAttribute{Exp: &ConfigExpression{Source: "body"}},
+				Timestamp: &ConfigTimestamp{
+					Exp:     op(CfgOpSplit, &ConfigExpression{Source: "body"}, lit(" "), lit("0")),
+					Layouts: []string{"rfc3339", "epoch_ms"},
+				},
+				Format: CfgFormatEvent,
+			},
+		},
+	}
+	require.NoError(t, cfg.Validate())
+	m, err := newProfileMatcher(cfg)
+	require.NoError(t, err)
+	bpt, reader := newTestTelemetry(t)
+	m.telemetry = bpt
+
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	ils := rl.ScopeLogs().AppendEmpty()
+	lr := ils.LogRecords().AppendEmpty()
+	lr.SetObservedTimestamp(1)
+
+	lr.Body().SetStr("1709287200123 disk full")
+	gen, _, err := m.MatchProfile(zap.NewNop(), rl, ils, lr)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, time.March, 1, 10, 0, 0, 123000000, time.UTC), lr.Timestamp().AsTime())
+	assert.Equal(t, "ze_tm=1709287200123,msg=2024-03-01T10:00:00.123Z UNKNOWN 1709287200123 disk full", gen.Message)
+
+	lr.SetTimestamp(0)
+	lr.Body().SetStr("yesterday disk full")
+	_, _, err = m.MatchProfile(zap.NewNop(), rl, ils, lr)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), uint64(lr.Timestamp()))
+	assert.Equal(t, map[string]int64{"0": 1}, counterValues(t, reader, "timestamp_parse_failures", "profile"))
+
+	cfg.Profiles[0].Timestamp.Layouts = []string{"%Q"}
+	assert.ErrorContains(t, cfg.Validate(), "profile 0 timestamp has invalid layout")
+	cfg.Profiles[0].Timestamp = &ConfigTimestamp{}
+	assert.ErrorContains(t, cfg.Validate(), "profile 0 timestamp requires exp")
+}
+
+func TestZoneCacheLimit(t *testing.T) {
+	var zc zoneCache
+	for i := 0; i < maxCachedZoneErrors+5; i++ {
+		_, first, err := zc.location(fmt.Sprintf("Mars/Crater_%d", i))
+		assert.Error(t, err)
+		assert.Equal(t, i < maxCachedZoneErrors, first)
+	}
+	assert.Len(t, zc.errors, maxCachedZoneErrors)
+
+	// cached failures are reported once
+	_, first, err := zc.location("Mars/Crater_0")
+	assert.Error(t, err)
+	assert.False(t, first)
+
+	// valid zones have their own limit
+	loc, first, err := zc.location("Europe/Berlin")
+	require.NoError(t, err)
+	assert.True(t, first)
+	assert.Equal(t, "Europe/Berlin", loc.String())
+	for len(zc.zones) < maxCachedZones {
+		zc.zones[fmt.Sprintf("Zone_%d", len(zc.zones))] = time.UTC
+	}
+	loc, first, err = zc.location("Asia/Tokyo")
+	require.NoError(t, err)
+	assert.False(t, first)
+	assert.Equal(t, "Asia/Tokyo", loc.String())
+	assert.Len(t, zc.zones, maxCachedZones)
+}
+
+func TestProfileTz(t *testing.T) {
+	cfg := &Config{
+		Profiles: []ConfigProfile{
+			{
+				ServiceGroup: &ConfigAttribute{Exp: lit("default"), Rename: "ze_deployment_name"},
+				Host:         &ConfigAttribute{Exp: lit("host1"), Rename: "host"},
+				Logbasename:  &ConfigAttribute{Exp: lit("app"), Rename: "logbasename"},
+				Message:      &ConfigAttribute{Exp: &ConfigExpression{Source: "body"}},
+				Timestamp: &ConfigTimestamp{
+					Exp:     &ConfigExpression{Source: "attr:time"},
+					Layouts: []string{"2006-01-02 15:04:05"},
+				},
+				Tz: &ConfigAttribute{Exp: op(CfgOpOr, &ConfigExpression{Source: "rattr:tz"}, lit("Asia/Tokyo"))},
+			},
+		},
+	}
+	require.NoError(t, cfg.Validate())
+	m, err := newProfileMatcher(cfg)
+	require.NoError(t, err)
+
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	ils := rl.ScopeLogs().AppendEmpty()
+	lr := ils.LogRecords().AppendEmpty()
+	lr.Body().SetStr("disk full")
+	lr.Attributes().PutStr("time", "2024-03-01 10:00:00")
+
+	_, req, err := m.MatchProfile(zap.NewNop(), rl, ils, lr)
+	require.NoError(t, err)
+	assert.Equal(t, "Asia/Tokyo", req.Tz)
+	assert.Equal(t, time.Date(2024, time.March, 1, 1, 0, 0, 0, time.UTC), lr.Timestamp().AsTime())
+
+	rl.Resource().Attributes().PutStr("tz", "America/New_York")
+	_, req, err = m.MatchProfile(zap.NewNop(), rl, ils, lr)
+	require.NoError(t, err)
+	assert.Equal(t, "America/New_York", req.Tz)
+	assert.Equal(t, time.Date(2024, time.March, 1, 15, 0, 0, 0, time.UTC), lr.Timestamp().AsTime())
+
+	// unknown zones keep the collector's zone and are reported once
+	rl.Resource().Attributes().PutStr("tz", "Mars/Olympus_Mons")
+	core, logs := observer.New(zap.InfoLevel)
+	for i := 0; i < 3; i++ {
+		_, req, err = m.MatchProfile(zap.New(core), rl, ils, lr)
+		require.NoError(t, err)
+		assert.Equal(t, time.Local.String(), req.Tz)
+	}
+	assert.Equal(t, 1, logs.FilterMessage("failed to load timezone").Len())
+	assert.Error(t, m.zones.errors["Mars/Olympus_Mons"])
+
+	cfg.Profiles[0].Tz = &ConfigAttribute{Exp: lit("Mars/Olympus_Mons")}
+	assert.ErrorContains(t, cfg.Validate(), "profile 0 tz has invalid zone Mars/Olympus_Mons")
+	cfg.Profiles[0].Tz = &ConfigAttribute{Exp: lit("Europe/Berlin")}
+	assert.NoError(t, cfg.Validate())
+}
@@ -41,12 +41,14 @@ const (
 	CfgFormatMessage   string = "message"
 	CfgFormatContainer string = "container"
 	CfgFormatEvent     string = "event"
+	CfgFormatJSON      string = "json"
 )
 
 var cfgFormatMap map[string]struct{} = map[string]struct{}{
 	CfgFormatMessage:   {},
 	CfgFormatContainer: {},
 	CfgFormatEvent:     {},
+	CfgFormatJSON:      {},
 }
 
 func keysForMap(mymap map[string]struct{}) []string {